@@ -1,76 +1,137 @@
 package poloniex
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"strconv"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/Charrette/poloniex/helper"
-	"github.com/sirupsen/logrus"
 )
 
+// DefaultTimeout bounds every request, so that a hung connection never blocks forever
+// even when the provided context has no deadline.
+const DefaultTimeout = 30 * time.Second
+
 type client struct {
-	key    string
-	secret string
+	key        string
+	secret     string
+	publicURL  string
+	tradeURL   string
+	httpClient *http.Client
+	logger     Logger
+	nonce      NonceSource
+	now        func() time.Time
+	userAgent  string
+
+	publicLimiter *RateLimiter
+	tradeLimiter  *RateLimiter
+
+	publicRetry *RetryPolicy
+	tradeRetry  *RetryPolicy
+
+	parseMode         ParseMode
+	skippedRowHandler func(*ParseError)
+
+	minOrderTotals map[string]Decimal
+
+	withdrawalGuard *withdrawalGuard
 }
 
 // New instantiates a Poloniex client as a Poloniex interface.
-func New(key, secret string) Poloniex {
-	if key == "" || secret == "" {
-		logrus.Warn("unable to retrieve Poloniex API credentials from environment. Only public calls will work.")
+// Options can be provided to override the defaults, see WithBaseURL, WithHTTPClient, etc.
+func New(key, secret string, opts ...Option) Poloniex {
+	c := defaultClient(key, secret)
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	// Built after the options so that it uses the provided clock.
+	if c.nonce == nil {
+		c.nonce = &NonceManager{now: c.now}
 	}
 
-	return &client{
-		key:    key,
-		secret: secret,
+	if key == "" || secret == "" {
+		c.logger.Warnf("unable to retrieve Poloniex API credentials from environment. Only public calls will work.")
 	}
+
+	return c
 }
 
-func (c *client) GetTickers() ([]*Ticker, error) {
-	tickersMap := make(map[string]*Ticker)
+func (c *client) GetTickers(ctx context.Context) ([]*Ticker, error) {
+	tickersMap := make(map[CurrencyPair]*RawTicker)
 
-	if err := c.publicCall("returnTicker", &tickersMap); err != nil {
+	if err := c.publicCall(ctx, "returnTicker", &tickersMap); err != nil {
 		return nil, err
 	}
 
+	p := c.newRowParser("returnTicker")
+
 	tickers := []*Ticker{}
 	for k, v := range tickersMap {
-		v.Currency = k
-		tickers = append(tickers, v)
+		ticker, ok := c.convertTicker(p.row(k.String()), k, v)
+		if !ok {
+			continue
+		}
+
+		tickers = append(tickers, ticker)
+	}
+
+	if err := p.err(); err != nil {
+		return nil, err
 	}
 
 	return tickers, nil
 }
 
-func (c *client) Get24hVolume() (*Volume24h, error) {
+func (c *client) convertTicker(row *parsedRow, currencyPair CurrencyPair, raw *RawTicker) (*Ticker, bool) {
+	ticker := &Ticker{
+		Pair:          currencyPair,
+		ID:            raw.ID,
+		Last:          row.decimal("last", raw.Last),
+		LowestAsk:     row.decimal("lowestAsk", raw.LowestAsk),
+		HighestBid:    row.decimal("highestBid", raw.HighestBid),
+		PercentChange: row.decimal("percentChange", raw.PercentChange),
+		BaseVolume:    row.decimal("baseVolume", raw.BaseVolume),
+		QuoteVolume:   row.decimal("quoteVolume", raw.QuoteVolume),
+		IsFrozen:      raw.IsFrozen == "1",
+		High24hr:      row.decimal("high24hr", raw.High24hr),
+		Low24hr:       row.decimal("low24hr", raw.Low24hr),
+		Raw:           *raw,
+	}
+
+	return ticker, !row.invalid
+}
+
+func (c *client) Get24hVolume(ctx context.Context) (*Volume24h, error) {
 	dest := make(map[string]*json.RawMessage)
 
-	if err := c.publicCall("return24hVolume", &dest); err != nil {
+	if err := c.publicCall(ctx, "return24hVolume", &dest); err != nil {
 		return nil, err
 	}
 
 	volume := &Volume24h{
-		PrimaryCurrenciesTotals: make(map[string]string),
-		Markets:                 make(map[string]map[string]string),
+		PrimaryCurrenciesTotals: make(map[string]Decimal),
+		Markets:                 make(map[CurrencyPair]map[string]Decimal),
 	}
 
 	for k, v := range dest {
-		marketVolume := map[string]string{}
+		marketVolume := map[string]Decimal{}
 
 		// First try to unmarshal result into a map of string.
 		// Meaning it's a market volume.
 		err := json.Unmarshal(*v, &marketVolume)
 		if err != nil {
-			var primaryCurrencyTotal string
+			var primaryCurrencyTotal Decimal
 
 			// If first unmarshal failed, then it might be the total of a primary currency.
-			// So we try to unmarshal it into a string.
+			// So we try to unmarshal it into a decimal.
 			e := json.Unmarshal(*v, &primaryCurrencyTotal)
 			if e != nil {
 				return nil, err
@@ -81,7 +142,7 @@ func (c *client) Get24hVolume() (*Volume24h, error) {
 			continue
 		}
 
-		volume.Markets[k] = marketVolume
+		volume.Markets[CurrencyPair(k)] = marketVolume
 	}
 
 	return volume, nil
@@ -91,111 +152,110 @@ func (c *client) Get24hVolume() (*Volume24h, error) {
 // I use this struct only to unmarshal the result,
 // then a conversion is made to return a clean OrderBook structure.
 type orderBookFromJSON struct {
-	Asks     [][]interface{} `json:"asks"`
-	Bids     [][]interface{} `json:"bids"`
-	IsFrozen string          `json:"isFrozen"`
-	Seq      int64           `json:"seq"`
+	Asks     [][]json.RawMessage `json:"asks"`
+	Bids     [][]json.RawMessage `json:"bids"`
+	IsFrozen string              `json:"isFrozen"`
+	Seq      int64               `json:"seq"`
 }
 
-func (c *client) GetOrderBook(currencyPair string, depth uint) (*OrderBook, error) {
-	if currencyPair == "all" {
+func (c *client) GetOrderBook(ctx context.Context, currencyPair CurrencyPair, depth uint) (*OrderBook, error) {
+	if currencyPair == AllPairs {
 		return nil, errors.New("use GetAllOrderBook to get all order book")
 	}
 
+	if err := currencyPair.Validate(); err != nil {
+		return nil, err
+	}
+
 	o := &orderBookFromJSON{}
 
 	params := []queryParam{
-		queryParam{key: "currencyPair", value: currencyPair},
+		queryParam{key: "currencyPair", value: currencyPair.String()},
 		queryParam{key: "depth", value: fmt.Sprintf("%v", depth)},
 	}
 
-	if err := c.publicCall("returnOrderBook", o, params...); err != nil {
+	if err := c.publicCall(ctx, "returnOrderBook", o, params...); err != nil {
 		return nil, err
 	}
 
-	orderBook := c.convertOrderBook(o)
-	orderBook.Pair = currencyPair
+	p := c.newRowParser("returnOrderBook")
+
+	orderBook := c.convertOrderBook(p, currencyPair, o)
+	if err := p.err(); err != nil {
+		return nil, err
+	}
 
 	return orderBook, nil
 }
 
-func (c *client) GetAllOrderBooks(depth uint) ([]*OrderBook, error) {
-	o := make(map[string]*orderBookFromJSON)
+func (c *client) GetAllOrderBooks(ctx context.Context, depth uint) ([]*OrderBook, error) {
+	o := make(map[CurrencyPair]*orderBookFromJSON)
 
 	params := []queryParam{
-		queryParam{key: "currencyPair", value: "all"},
+		queryParam{key: "currencyPair", value: AllPairs.String()},
 		queryParam{key: "depth", value: fmt.Sprintf("%v", depth)},
 	}
 
-	if err := c.publicCall("returnOrderBook", &o, params...); err != nil {
+	if err := c.publicCall(ctx, "returnOrderBook", &o, params...); err != nil {
 		return nil, err
 	}
 
+	p := c.newRowParser("returnOrderBook")
+
 	orderBooks := []*OrderBook{}
 	for k, v := range o {
-		orderBook := c.convertOrderBook(v)
-		orderBook.Pair = k
+		orderBooks = append(orderBooks, c.convertOrderBook(p, k, v))
+	}
 
-		orderBooks = append(orderBooks, orderBook)
+	if err := p.err(); err != nil {
+		return nil, err
 	}
 
 	return orderBooks, nil
 }
 
-func (c *client) convertOrderBook(o *orderBookFromJSON) *OrderBook {
+func (c *client) convertOrderBook(p *rowParser, currencyPair CurrencyPair, o *orderBookFromJSON) *OrderBook {
 	orderBook := &OrderBook{
+		Pair:     currencyPair,
+		Asks:     c.convertOrders(p, currencyPair, "asks", o.Asks),
+		Bids:     c.convertOrders(p, currencyPair, "bids", o.Bids),
 		IsFrozen: o.IsFrozen,
 		Seq:      o.Seq,
 	}
 
-	for _, a := range o.Asks {
-		if len(a) < 2 {
-			continue
-		}
-
-		// The first value is a string instead of being a float,
-		// god knows why?
-		value, err := strconv.ParseFloat(fmt.Sprintf("%v", a[0]), 64)
-		if err != nil {
-			continue
-		}
+	return orderBook
+}
 
-		// But the second value is a float :)
-		amount, ok := a[1].(float64)
-		if !ok {
-			continue
-		}
+func (c *client) convertOrders(p *rowParser, currencyPair CurrencyPair, side string, rows [][]json.RawMessage) []*Order {
+	orders := []*Order{}
+	for i, r := range rows {
+		row := p.row(fmt.Sprintf("%s %s[%d]", currencyPair, side, i))
 
-		orderBook.Asks = append(orderBook.Asks, &Order{Value: value, Amount: amount})
-	}
+		if len(r) < 2 {
+			row.fail("order", fmt.Sprintf("%s", r), errors.New("expected a rate and an amount"))
 
-	for _, b := range o.Bids {
-		if len(b) < 2 {
 			continue
 		}
 
-		// The first value is a string instead of being a float,
-		// god knows why?
-		value, err := strconv.ParseFloat(fmt.Sprintf("%v", b[0]), 64)
-		if err != nil {
-			continue
+		// The first value is a string whereas the second one is a number,
+		// god knows why? Decimal handles both.
+		order := &Order{
+			Value:  row.jsonDecimal("rate", r[0]),
+			Amount: row.jsonDecimal("amount", r[1]),
 		}
-
-		// But the second value is a float :)
-		amount, ok := b[1].(float64)
-		if !ok {
+		if row.invalid {
 			continue
 		}
 
-		orderBook.Bids = append(orderBook.Bids, &Order{Value: value, Amount: amount})
+		orders = append(orders, order)
 	}
 
-	return orderBook
+	return orders
 }
 
-// As the response from Poloniex for returnTradeHistory contains some strings that should be floats,
+// As the response from Poloniex for returnTradeHistory contains some strings that should be numbers,
 // I use this struct only to unmarshal the result,
-// then a conversion is made to return a clean TradeHistory structure with floats where needed.
+// then a conversion is made to return a clean TradeHistory structure with decimals where needed.
 type tradeHistoryFromJSON struct {
 	GlobalTradeID int64  `json:"globalTradeID"`
 	TradeID       int64  `json:"tradeID"`
@@ -206,73 +266,90 @@ type tradeHistoryFromJSON struct {
 	Total         string `json:"total"`
 }
 
-func (c *client) GetTradeHistory(currencyPair string, start, end uint64) ([]*TradeHistory, error) {
+func (c *client) GetTradeHistory(ctx context.Context, currencyPair CurrencyPair, start, end time.Time) ([]*TradeHistory, error) {
+	if err := currencyPair.Validate(); err != nil {
+		return nil, err
+	}
+
 	tradeHistoryFromJSON := []*tradeHistoryFromJSON{}
 
 	params := []queryParam{
-		queryParam{key: "currencyPair", value: currencyPair},
+		queryParam{key: "currencyPair", value: currencyPair.String()},
 	}
-	if start != 0 && end != 0 {
-		params = append(params, queryParam{key: "start", value: fmt.Sprintf("%v", start)})
-		params = append(params, queryParam{key: "end", value: fmt.Sprintf("%v", end)})
+	if !start.IsZero() && !end.IsZero() {
+		params = append(params, queryParam{key: "start", value: fmt.Sprintf("%v", start.Unix())})
+		params = append(params, queryParam{key: "end", value: fmt.Sprintf("%v", end.Unix())})
 	}
 
-	if err := c.publicCall("returnTradeHistory", &tradeHistoryFromJSON, params...); err != nil {
+	if err := c.publicCall(ctx, "returnTradeHistory", &tradeHistoryFromJSON, params...); err != nil {
 		return nil, err
 	}
 
+	p := c.newRowParser("returnTradeHistory")
+
 	tradeHistory := []*TradeHistory{}
 	for _, t := range tradeHistoryFromJSON {
-		rate, err := strconv.ParseFloat(t.Rate, 64)
-		if err != nil {
-			continue
-		}
-
-		amount, err := strconv.ParseFloat(t.Amount, 64)
-		if err != nil {
+		th, ok := c.convertTradeHistory(p.row(fmt.Sprintf("%s trade %d", currencyPair, t.TradeID)), t)
+		if !ok {
 			continue
 		}
 
-		total, err := strconv.ParseFloat(t.Total, 64)
-		if err != nil {
-			continue
-		}
+		tradeHistory = append(tradeHistory, th)
+	}
 
-		tradeHistory = append(tradeHistory, &TradeHistory{
-			GlobalTradeID: t.GlobalTradeID,
-			TradeID:       t.TradeID,
-			Date:          t.Date,
-			Type:          t.Type,
-			Rate:          rate,
-			Amount:        amount,
-			Total:         total,
-		})
+	if err := p.err(); err != nil {
+		return nil, err
 	}
 
 	return tradeHistory, nil
 }
 
-func (c *client) GetChartData(currencyPair string, start, end uint64, period ChartDataPeriod) ([]*ChartData, error) {
+func (c *client) convertTradeHistory(row *parsedRow, t *tradeHistoryFromJSON) (*TradeHistory, bool) {
+	tradeHistory := &TradeHistory{
+		GlobalTradeID: t.GlobalTradeID,
+		TradeID:       t.TradeID,
+		Date:          row.date("date", t.Date),
+		Type:          row.side("type", t.Type),
+		Rate:          row.decimal("rate", t.Rate),
+		Amount:        row.decimal("amount", t.Amount),
+		Total:         row.decimal("total", t.Total),
+	}
+
+	return tradeHistory, !row.invalid
+}
+
+// Format of the dates returned by Poloniex, always in UTC.
+const dateLayout = "2006-01-02 15:04:05"
+
+func parseDate(s string) (time.Time, error) {
+	return time.ParseInLocation(dateLayout, s, time.UTC)
+}
+
+func (c *client) GetChartData(ctx context.Context, currencyPair CurrencyPair, start, end uint64, period ChartDataPeriod) ([]*ChartData, error) {
+	if err := currencyPair.Validate(); err != nil {
+		return nil, err
+	}
+
 	chartData := []*ChartData{}
 
 	params := []queryParam{
-		queryParam{key: "currencyPair", value: currencyPair},
+		queryParam{key: "currencyPair", value: currencyPair.String()},
 		queryParam{key: "start", value: fmt.Sprintf("%v", start)},
 		queryParam{key: "end", value: fmt.Sprintf("%v", end)},
 		queryParam{key: "period", value: fmt.Sprintf("%v", period)},
 	}
 
-	if err := c.publicCall("returnChartData", &chartData, params...); err != nil {
+	if err := c.publicCall(ctx, "returnChartData", &chartData, params...); err != nil {
 		return nil, err
 	}
 
 	return chartData, nil
 }
 
-func (c *client) GetCurrencies() ([]*Currency, error) {
+func (c *client) GetCurrencies(ctx context.Context) ([]*Currency, error) {
 	currenciesMap := make(map[string]*Currency)
 
-	if err := c.publicCall("returnCurrencies", &currenciesMap); err != nil {
+	if err := c.publicCall(ctx, "returnCurrencies", &currenciesMap); err != nil {
 		return nil, err
 	}
 
@@ -285,9 +362,9 @@ func (c *client) GetCurrencies() ([]*Currency, error) {
 	return currencies, nil
 }
 
-// As the response from Poloniex for returnLoanOrders contains some strings that should be floats,
+// As the response from Poloniex for returnLoanOrders contains some strings that should be numbers,
 // I use this struct only to unmarshal the result,
-// then a conversion is made to return a clean LoanOrders structure with floats where needed.
+// then a conversion is made to return a clean LoanOrders structure with decimals where needed.
 type loanFromJSON struct {
 	Rate     string `json:"rate"`
 	Amount   string `json:"amount"`
@@ -295,62 +372,54 @@ type loanFromJSON struct {
 	RangeMax int64  `json:"rangeMax"`
 }
 
-// As the response from Poloniex for returnLoanOrders contains some strings that should be floats,
+// As the response from Poloniex for returnLoanOrders contains some strings that should be numbers,
 // I use this struct only to unmarshal the result,
-// then a conversion is made to return a clean LoanOrders structure with floats where needed.
+// then a conversion is made to return a clean LoanOrders structure with decimals where needed.
 type loanOrdersFromJSON struct {
 	Offers  []*loanFromJSON `json:"offers"`
 	Demands []*loanFromJSON `json:"demands"`
 }
 
-func (c *client) GetLoanOrders(currency string) (*LoanOrders, error) {
+func (c *client) GetLoanOrders(ctx context.Context, currency string) (*LoanOrders, error) {
 	loanOrdersFromJSON := &loanOrdersFromJSON{}
 
-	if err := c.publicCall("returnLoanOrders", loanOrdersFromJSON, queryParam{key: "currency", value: currency}); err != nil {
+	if err := c.publicCall(ctx, "returnLoanOrders", loanOrdersFromJSON, queryParam{key: "currency", value: currency}); err != nil {
 		return nil, err
 	}
 
-	loanOrders := &LoanOrders{}
-
-	for _, o := range loanOrdersFromJSON.Offers {
-		rate, err := strconv.ParseFloat(o.Rate, 64)
-		if err != nil {
-			continue
-		}
+	p := c.newRowParser("returnLoanOrders")
 
-		amount, err := strconv.ParseFloat(o.Amount, 64)
-		if err != nil {
-			continue
-		}
+	loanOrders := &LoanOrders{
+		Offers:  c.convertLoans(p, currency, "offers", loanOrdersFromJSON.Offers),
+		Demands: c.convertLoans(p, currency, "demands", loanOrdersFromJSON.Demands),
+	}
 
-		loanOrders.Offers = append(loanOrders.Offers, &Loan{
-			Rate:     rate,
-			Amount:   amount,
-			RangeMin: o.RangeMin,
-			RangeMax: o.RangeMax,
-		})
+	if err := p.err(); err != nil {
+		return nil, err
 	}
 
-	for _, d := range loanOrdersFromJSON.Demands {
-		rate, err := strconv.ParseFloat(d.Rate, 64)
-		if err != nil {
-			continue
-		}
+	return loanOrders, nil
+}
 
-		amount, err := strconv.ParseFloat(d.Amount, 64)
-		if err != nil {
+func (c *client) convertLoans(p *rowParser, currency, side string, loansFromJSON []*loanFromJSON) []*Loan {
+	loans := []*Loan{}
+	for i, l := range loansFromJSON {
+		row := p.row(fmt.Sprintf("%s %s[%d]", currency, side, i))
+
+		loan := &Loan{
+			Rate:     row.decimal("rate", l.Rate),
+			Amount:   row.decimal("amount", l.Amount),
+			RangeMin: l.RangeMin,
+			RangeMax: l.RangeMax,
+		}
+		if row.invalid {
 			continue
 		}
 
-		loanOrders.Demands = append(loanOrders.Demands, &Loan{
-			Rate:     rate,
-			Amount:   amount,
-			RangeMin: d.RangeMin,
-			RangeMax: d.RangeMax,
-		})
+		loans = append(loans, loan)
 	}
 
-	return loanOrders, nil
+	return loans
 }
 
 type queryParam struct {
@@ -358,10 +427,20 @@ type queryParam struct {
 	value string
 }
 
-func (c *client) publicCall(command string, dest interface{}, queryParams ...queryParam) error {
-	req, err := http.NewRequest("GET", PublicAPI, nil)
+func (c *client) publicCall(ctx context.Context, command string, dest interface{}, queryParams ...queryParam) error {
+	return c.retry(ctx, c.publicRetry, command, func() error {
+		return c.doPublicCall(ctx, command, dest, queryParams...)
+	})
+}
+
+func (c *client) doPublicCall(ctx context.Context, command string, dest interface{}, queryParams ...queryParam) error {
+	if err := waitRateLimiter(ctx, c.publicLimiter); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.publicURL, nil)
 	if err != nil {
-		logrus.WithError(err).Info("unable to create GET request")
+		c.logger.Infof("unable to create GET request: %v", err)
 
 		return err
 	}
@@ -375,20 +454,24 @@ func (c *client) publicCall(command string, dest interface{}, queryParams ...que
 
 	req.URL.RawQuery = query.Encode()
 
-	return c.processRequest(req, dest)
+	return c.processRequest(req, command, dest)
 }
 
-func (c *client) GetBalances() ([]*Balance, error) {
+func (c *client) GetBalances(ctx context.Context) ([]*Balance, error) {
 	balancesFromJSON := make(map[string]string)
 
-	if err := c.tradeCall("returnBalances", &balancesFromJSON); err != nil {
+	if err := c.tradeCall(ctx, "returnBalances", &balancesFromJSON); err != nil {
 		return nil, err
 	}
 
+	p := c.newRowParser("returnBalances")
+
 	balances := []*Balance{}
 	for k, v := range balancesFromJSON {
-		amount, err := strconv.ParseFloat(v, 64)
-		if err != nil {
+		row := p.row(k)
+
+		amount := row.decimal("amount", v)
+		if row.invalid {
 			continue
 		}
 
@@ -398,19 +481,23 @@ func (c *client) GetBalances() ([]*Balance, error) {
 		})
 	}
 
+	if err := p.err(); err != nil {
+		return nil, err
+	}
+
 	return balances, nil
 }
 
-// As the response from Poloniex for returnCompleteBalances contains some strings that should be floats,
+// As the response from Poloniex for returnCompleteBalances contains some strings that should be numbers,
 // I use this struct only to unmarshal the result,
-// then a conversion is made to return a clean Balance structure with floats where needed.
+// then a conversion is made to return a clean Balance structure with decimals where needed.
 type balanceFromJSON struct {
 	Available string `json:"available"`
 	OnOrders  string `json:"onOrders"`
 	BTCValue  string `json:"btcValue"`
 }
 
-func (c *client) GetCompleteBalances(account BalanceAccount) ([]*CompleteBalance, error) {
+func (c *client) GetCompleteBalances(ctx context.Context, account BalanceAccount) ([]*CompleteBalance, error) {
 	balancesFromJSON := make(map[string]*balanceFromJSON)
 
 	params := []postParam{}
@@ -421,36 +508,590 @@ func (c *client) GetCompleteBalances(account BalanceAccount) ([]*CompleteBalance
 		})
 	}
 
-	if err := c.tradeCall("returnCompleteBalances", &balancesFromJSON, params...); err != nil {
+	if err := c.tradeCall(ctx, "returnCompleteBalances", &balancesFromJSON, params...); err != nil {
 		return nil, err
 	}
 
+	p := c.newRowParser("returnCompleteBalances")
+
 	completeBalances := []*CompleteBalance{}
 	for k, v := range balancesFromJSON {
-		a, err := strconv.ParseFloat(v.Available, 64)
-		if err != nil {
+		row := p.row(k)
+
+		completeBalance := &CompleteBalance{
+			Currency:  k,
+			Available: row.decimal("available", v.Available),
+			OnOrders:  row.decimal("onOrders", v.OnOrders),
+			BTCValue:  row.decimal("btcValue", v.BTCValue),
+		}
+		if row.invalid {
 			continue
 		}
 
-		o, err := strconv.ParseFloat(v.OnOrders, 64)
-		if err != nil {
+		completeBalances = append(completeBalances, completeBalance)
+	}
+
+	if err := p.err(); err != nil {
+		return nil, err
+	}
+
+	return completeBalances, nil
+}
+
+func (c *client) GetAvailableAccountBalances(ctx context.Context, account BalanceAccount) (map[BalanceAccount][]*Balance, error) {
+	if account != AllAccounts && !account.IsTransferable() {
+		return nil, fmt.Errorf("poloniex: invalid account %q", account)
+	}
+
+	// Poloniex returns an empty array instead of an empty map for accounts without any balance,
+	// and when there is no account at all, so each account is decoded separately.
+	raw := json.RawMessage{}
+
+	params := []postParam{}
+	if account != AllAccounts {
+		params = append(params, postParam{key: "account", value: string(account)})
+	}
+
+	if err := c.tradeCall(ctx, "returnAvailableAccountBalances", &raw, params...); err != nil {
+		return nil, err
+	}
+
+	accountBalances := make(map[BalanceAccount][]*Balance)
+	if isEmptyJSONArray(raw) {
+		return accountBalances, nil
+	}
+
+	accountsFromJSON := make(map[BalanceAccount]json.RawMessage)
+	if err := json.Unmarshal(raw, &accountsFromJSON); err != nil {
+		return nil, fmt.Errorf("poloniex: unable to decode returnAvailableAccountBalances response: %v", err)
+	}
+
+	p := c.newRowParser("returnAvailableAccountBalances")
+
+	for k, v := range accountsFromJSON {
+		if isEmptyJSONArray(v) {
 			continue
 		}
 
-		b, err := strconv.ParseFloat(v.BTCValue, 64)
-		if err != nil {
+		balancesFromJSON := make(map[string]string)
+		if err := json.Unmarshal(v, &balancesFromJSON); err != nil {
+			p.row(string(k)).fail("balances", string(v), err)
+
 			continue
 		}
 
-		completeBalances = append(completeBalances, &CompleteBalance{
-			Currency:  k,
-			Available: a,
-			OnOrders:  o,
-			BTCValue:  b,
-		})
+		balances := []*Balance{}
+		for currency, amount := range balancesFromJSON {
+			row := p.row(fmt.Sprintf("%s %s", k, currency))
+
+			balance := &Balance{
+				Currency: currency,
+				Amount:   row.decimal("amount", amount),
+			}
+			if row.invalid {
+				continue
+			}
+
+			balances = append(balances, balance)
+		}
+
+		accountBalances[k] = balances
 	}
 
-	return completeBalances, nil
+	if err := p.err(); err != nil {
+		return nil, err
+	}
+
+	return accountBalances, nil
+}
+
+type transferResultFromJSON struct {
+	Success int    `json:"success"`
+	Message string `json:"message"`
+}
+
+func (c *client) TransferBalance(ctx context.Context, currency string, amount Decimal, from, to BalanceAccount) (*TransferResult, error) {
+	if !from.IsTransferable() || !to.IsTransferable() || from == to {
+		return nil, fmt.Errorf("poloniex: unable to transfer from %q to %q", from, to)
+	}
+
+	if amount.Sign() <= 0 {
+		return nil, fmt.Errorf("poloniex: transfer amount must be positive, got %s", amount)
+	}
+
+	params := []postParam{
+		postParam{key: "currency", value: currency},
+		postParam{key: "amount", value: amount.String()},
+		postParam{key: "fromAccount", value: string(from)},
+		postParam{key: "toAccount", value: string(to)},
+	}
+
+	t := &transferResultFromJSON{}
+
+	if err := c.tradeCall(ctx, "transferBalance", t, params...); err != nil {
+		return nil, err
+	}
+
+	if t.Success != 1 {
+		return nil, newAPIError(http.StatusOK, "transferBalance", t.Message)
+	}
+
+	return &TransferResult{Message: t.Message}, nil
+}
+
+func (c *client) GetDepositAddresses(ctx context.Context) (map[string]string, error) {
+	// Poloniex returns an empty array instead of an empty map when there is no address.
+	raw := json.RawMessage{}
+
+	if err := c.tradeCall(ctx, "returnDepositAddresses", &raw); err != nil {
+		return nil, err
+	}
+
+	addresses := make(map[string]string)
+	if isEmptyJSONArray(raw) {
+		return addresses, nil
+	}
+
+	if err := json.Unmarshal(raw, &addresses); err != nil {
+		return nil, fmt.Errorf("poloniex: unable to decode returnDepositAddresses response: %v", err)
+	}
+
+	return addresses, nil
+}
+
+// Response from Poloniex for generateNewAddress.
+// Response is the new address on success, or a message explaining why none was generated.
+type generatedAddressFromJSON struct {
+	Success  int    `json:"success"`
+	Response string `json:"response"`
+}
+
+func (c *client) GenerateNewAddress(ctx context.Context, currency string) (*GeneratedAddress, error) {
+	if currency == "" {
+		return nil, errors.New("currency is required to generate a new address")
+	}
+
+	g := &generatedAddressFromJSON{}
+
+	if err := c.tradeCall(ctx, "generateNewAddress", g, postParam{key: "currency", value: currency}); err != nil {
+		return nil, err
+	}
+
+	generatedAddress := &GeneratedAddress{
+		Currency:  currency,
+		Generated: g.Success == 1,
+		Response:  g.Response,
+	}
+	if generatedAddress.Generated {
+		generatedAddress.Address = g.Response
+	}
+
+	return generatedAddress, nil
+}
+
+// Amounts and fees of returnDepositsWithdrawals are strings, converted to decimals in Deposit and Withdrawal.
+type depositFromJSON struct {
+	Currency      string `json:"currency"`
+	Address       string `json:"address"`
+	Amount        string `json:"amount"`
+	Confirmations int64  `json:"confirmations"`
+	TxID          string `json:"txid"`
+	Timestamp     int64  `json:"timestamp"`
+	Status        string `json:"status"`
+}
+
+type withdrawalFromJSON struct {
+	WithdrawalNumber int64  `json:"withdrawalNumber"`
+	Currency         string `json:"currency"`
+	Address          string `json:"address"`
+	Amount           string `json:"amount"`
+	Fee              string `json:"fee"`
+	Timestamp        int64  `json:"timestamp"`
+	Status           string `json:"status"`
+	IPAddress        string `json:"ipAddress"`
+}
+
+type depositsWithdrawalsFromJSON struct {
+	Deposits    []*depositFromJSON    `json:"deposits"`
+	Withdrawals []*withdrawalFromJSON `json:"withdrawals"`
+}
+
+// Maximum range fetched by a single returnDepositsWithdrawals call.
+// Longer ranges are split, so that Poloniex never truncates the result.
+const depositsWithdrawalsWindow = 30 * 24 * time.Hour
+
+func (c *client) GetDepositsWithdrawals(ctx context.Context, start, end time.Time) (*DepositsWithdrawals, error) {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return nil, errors.New("a valid range is required to get deposits and withdrawals")
+	}
+
+	p := c.newRowParser("returnDepositsWithdrawals")
+
+	depositsWithdrawals := &DepositsWithdrawals{
+		Deposits:    []*Deposit{},
+		Withdrawals: []*Withdrawal{},
+	}
+
+	// Windows don't overlap, as Poloniex includes both bounds and works with seconds.
+	for windowStart := start.Truncate(time.Second); !windowStart.After(end); windowStart = windowStart.Add(depositsWithdrawalsWindow) {
+		windowEnd := windowStart.Add(depositsWithdrawalsWindow - time.Second)
+		if windowEnd.After(end) {
+			windowEnd = end
+		}
+
+		d := &depositsWithdrawalsFromJSON{}
+
+		params := []postParam{
+			postParam{key: "start", value: fmt.Sprintf("%v", windowStart.Unix())},
+			postParam{key: "end", value: fmt.Sprintf("%v", windowEnd.Unix())},
+		}
+
+		if err := c.tradeCall(ctx, "returnDepositsWithdrawals", d, params...); err != nil {
+			return nil, err
+		}
+
+		for _, v := range d.Deposits {
+			deposit, ok := c.convertDeposit(p.row(fmt.Sprintf("%s deposit %s", v.Currency, v.TxID)), v)
+			if !ok {
+				continue
+			}
+
+			depositsWithdrawals.Deposits = append(depositsWithdrawals.Deposits, deposit)
+		}
+
+		for _, v := range d.Withdrawals {
+			withdrawal, ok := c.convertWithdrawal(p.row(fmt.Sprintf("%s withdrawal %d", v.Currency, v.WithdrawalNumber)), v)
+			if !ok {
+				continue
+			}
+
+			depositsWithdrawals.Withdrawals = append(depositsWithdrawals.Withdrawals, withdrawal)
+		}
+	}
+
+	if err := p.err(); err != nil {
+		return nil, err
+	}
+
+	return depositsWithdrawals, nil
+}
+
+func (c *client) convertDeposit(row *parsedRow, d *depositFromJSON) (*Deposit, bool) {
+	deposit := &Deposit{
+		Currency:      d.Currency,
+		Address:       d.Address,
+		Amount:        row.decimal("amount", d.Amount),
+		Confirmations: d.Confirmations,
+		TxID:          d.TxID,
+		Date:          time.Unix(d.Timestamp, 0).UTC(),
+		Status:        d.Status,
+	}
+
+	return deposit, !row.invalid
+}
+
+func (c *client) convertWithdrawal(row *parsedRow, w *withdrawalFromJSON) (*Withdrawal, bool) {
+	withdrawal := &Withdrawal{
+		WithdrawalNumber: w.WithdrawalNumber,
+		Currency:         w.Currency,
+		Address:          w.Address,
+		Amount:           row.decimal("amount", w.Amount),
+		Date:             time.Unix(w.Timestamp, 0).UTC(),
+		Status:           w.Status,
+		IPAddress:        w.IPAddress,
+	}
+
+	// Older withdrawals don't have any fee.
+	if w.Fee != "" {
+		withdrawal.Fee = row.decimal("fee", w.Fee)
+	}
+
+	// Once complete, the status contains the transaction ID.
+	// Ex: "COMPLETE: 36e483efa6aff9fd53a235177579d98451c4eb237c210e66cd2b9a2d4a988f8e"
+	if i := strings.Index(w.Status, ":"); i >= 0 {
+		withdrawal.Status = strings.TrimSpace(w.Status[:i])
+		withdrawal.TxID = strings.TrimSpace(w.Status[i+1:])
+	}
+
+	return withdrawal, !row.invalid
+}
+
+// Rates and amounts of returnOpenOrders are strings, converted to decimals in OpenOrder.
+type openOrderFromJSON struct {
+	OrderNumber    int64  `json:"orderNumber,string"`
+	Type           string `json:"type"`
+	Rate           string `json:"rate"`
+	Amount         string `json:"amount"`
+	StartingAmount string `json:"startingAmount"`
+	Total          string `json:"total"`
+	Date           string `json:"date"`
+	Margin         int    `json:"margin"`
+}
+
+func (c *client) GetOpenOrders(ctx context.Context, currencyPair CurrencyPair) ([]*OpenOrder, error) {
+	if currencyPair == AllPairs {
+		return nil, errors.New("use GetAllOpenOrders to get open orders of all markets")
+	}
+
+	if err := currencyPair.Validate(); err != nil {
+		return nil, err
+	}
+
+	o := []*openOrderFromJSON{}
+
+	if err := c.tradeCall(ctx, "returnOpenOrders", &o, postParam{key: "currencyPair", value: currencyPair.String()}); err != nil {
+		return nil, err
+	}
+
+	p := c.newRowParser("returnOpenOrders")
+
+	openOrders := c.convertOpenOrders(p, currencyPair, o)
+	if err := p.err(); err != nil {
+		return nil, err
+	}
+
+	return openOrders, nil
+}
+
+func (c *client) GetAllOpenOrders(ctx context.Context) ([]*OpenOrder, error) {
+	// Poloniex returns an empty array instead of an empty map when there is no market.
+	raw := json.RawMessage{}
+
+	if err := c.tradeCall(ctx, "returnOpenOrders", &raw, postParam{key: "currencyPair", value: AllPairs.String()}); err != nil {
+		return nil, err
+	}
+
+	openOrders := []*OpenOrder{}
+	if isEmptyJSONArray(raw) {
+		return openOrders, nil
+	}
+
+	o := make(map[CurrencyPair][]*openOrderFromJSON)
+	if err := json.Unmarshal(raw, &o); err != nil {
+		return nil, fmt.Errorf("poloniex: unable to decode returnOpenOrders response: %v", err)
+	}
+
+	p := c.newRowParser("returnOpenOrders")
+
+	for k, v := range o {
+		openOrders = append(openOrders, c.convertOpenOrders(p, k, v)...)
+	}
+
+	if err := p.err(); err != nil {
+		return nil, err
+	}
+
+	return openOrders, nil
+}
+
+func (c *client) convertOpenOrders(p *rowParser, currencyPair CurrencyPair, o []*openOrderFromJSON) []*OpenOrder {
+	openOrders := []*OpenOrder{}
+	for _, v := range o {
+		row := p.row(fmt.Sprintf("%s order %d", currencyPair, v.OrderNumber))
+
+		openOrder := &OpenOrder{
+			Pair:        currencyPair,
+			OrderNumber: v.OrderNumber,
+			Type:        row.side("type", v.Type),
+			Rate:        row.decimal("rate", v.Rate),
+			Amount:      row.decimal("amount", v.Amount),
+			Total:       row.decimal("total", v.Total),
+			Date:        row.date("date", v.Date),
+			Margin:      v.Margin == 1,
+		}
+
+		// The starting amount isn't returned for old orders.
+		if v.StartingAmount != "" {
+			openOrder.StartingAmount = row.decimal("startingAmount", v.StartingAmount)
+		}
+
+		if row.invalid {
+			continue
+		}
+
+		openOrders = append(openOrders, openOrder)
+	}
+
+	return openOrders
+}
+
+// Rates and amounts of the private returnTradeHistory are strings, converted to decimals in MyTrade.
+// Trade IDs are sometimes strings and sometimes numbers, json.Number handles both.
+type myTradeFromJSON struct {
+	GlobalTradeID int64       `json:"globalTradeID"`
+	TradeID       json.Number `json:"tradeID"`
+	OrderNumber   json.Number `json:"orderNumber"`
+	Date          string      `json:"date"`
+	Type          string      `json:"type"`
+	Rate          string      `json:"rate"`
+	Amount        string      `json:"amount"`
+	Total         string      `json:"total"`
+	Fee           string      `json:"fee"`
+	Category      string      `json:"category"`
+}
+
+// Maximum number of trades returned by a single private returnTradeHistory call.
+const myTradeHistoryPageSize = 10000
+
+func (c *client) GetMyTradeHistory(ctx context.Context, currencyPair CurrencyPair, start, end time.Time, limit int) ([]*MyTrade, error) {
+	if currencyPair != AllPairs {
+		if err := currencyPair.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if start.IsZero() && !end.IsZero() {
+		return nil, errors.New("poloniex: a start is required with an end to get your trade history")
+	}
+
+	if !start.IsZero() && end.IsZero() {
+		end = c.now()
+	}
+
+	p := c.newRowParser("returnTradeHistory")
+
+	trades := []*MyTrade{}
+	seen := make(map[int64]bool)
+
+	// Poloniex returns the most recent trades first,
+	// so pages are fetched by moving the end of the range back to the oldest trade received.
+	// As several trades can share the same second, the oldest one is fetched again,
+	// hence the deduplication on the global trade ID.
+	fullPages := false
+	for limit <= 0 || len(trades) < limit {
+		pageSize := myTradeHistoryPageSize
+		if !fullPages && limit > 0 && limit-len(trades) < pageSize {
+			pageSize = limit - len(trades)
+		}
+
+		page, received, err := c.getMyTradeHistoryPage(ctx, p, currencyPair, start, end, pageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		added := 0
+		oldest := end
+		for _, t := range page {
+			if t.Date.Before(oldest) {
+				oldest = t.Date
+			}
+
+			if seen[t.GlobalTradeID] {
+				continue
+			}
+			seen[t.GlobalTradeID] = true
+
+			trades = append(trades, t)
+			added++
+		}
+
+		// Pages can't be requested without a range.
+		if start.IsZero() || received < pageSize {
+			break
+		}
+
+		if added == 0 {
+			// The page was too small to get past the trades already received,
+			// fetch the same range again with full pages.
+			if pageSize < myTradeHistoryPageSize {
+				fullPages = true
+
+				continue
+			}
+
+			// More trades than a full page share the same second, skip it to avoid looping forever.
+			oldest = oldest.Add(-time.Second)
+		}
+
+		if oldest.Before(start) {
+			break
+		}
+		end = oldest
+	}
+
+	if err := p.err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(trades, func(i, j int) bool {
+		return trades[i].Date.After(trades[j].Date)
+	})
+
+	if limit > 0 && len(trades) > limit {
+		trades = trades[:limit]
+	}
+
+	return trades, nil
+}
+
+// getMyTradeHistoryPage returns the valid trades of a page, and the number of trades received.
+func (c *client) getMyTradeHistoryPage(ctx context.Context, p *rowParser, currencyPair CurrencyPair, start, end time.Time, limit int) ([]*MyTrade, int, error) {
+	params := []postParam{
+		postParam{key: "currencyPair", value: currencyPair.String()},
+		postParam{key: "limit", value: fmt.Sprintf("%v", limit)},
+	}
+	if !start.IsZero() && !end.IsZero() {
+		params = append(params, postParam{key: "start", value: fmt.Sprintf("%v", start.Unix())})
+		params = append(params, postParam{key: "end", value: fmt.Sprintf("%v", end.Unix())})
+	}
+
+	tradesByPair := make(map[CurrencyPair][]*myTradeFromJSON)
+
+	if currencyPair == AllPairs {
+		// Poloniex returns an empty array instead of an empty map when there is no trade.
+		raw := json.RawMessage{}
+		if err := c.tradeCall(ctx, "returnTradeHistory", &raw, params...); err != nil {
+			return nil, 0, err
+		}
+
+		if !isEmptyJSONArray(raw) {
+			if err := json.Unmarshal(raw, &tradesByPair); err != nil {
+				return nil, 0, fmt.Errorf("poloniex: unable to decode returnTradeHistory response: %v", err)
+			}
+		}
+	} else {
+		t := []*myTradeFromJSON{}
+		if err := c.tradeCall(ctx, "returnTradeHistory", &t, params...); err != nil {
+			return nil, 0, err
+		}
+
+		tradesByPair[currencyPair] = t
+	}
+
+	received := 0
+	trades := []*MyTrade{}
+	for k, v := range tradesByPair {
+		received += len(v)
+
+		for _, t := range v {
+			trade, ok := c.convertMyTrade(p.row(fmt.Sprintf("%s trade %d", k, t.GlobalTradeID)), k, t)
+			if !ok {
+				continue
+			}
+
+			trades = append(trades, trade)
+		}
+	}
+
+	return trades, received, nil
+}
+
+func (c *client) convertMyTrade(row *parsedRow, currencyPair CurrencyPair, t *myTradeFromJSON) (*MyTrade, bool) {
+	trade := &MyTrade{
+		Pair:          currencyPair,
+		GlobalTradeID: t.GlobalTradeID,
+		TradeID:       row.integer("tradeID", t.TradeID.String()),
+		OrderNumber:   row.integer("orderNumber", t.OrderNumber.String()),
+		Date:          row.date("date", t.Date),
+		Type:          row.side("type", t.Type),
+		Rate:          row.decimal("rate", t.Rate),
+		Amount:        row.decimal("amount", t.Amount),
+		Total:         row.decimal("total", t.Total),
+		Fee:           row.decimal("fee", t.Fee),
+		Category:      TradeCategory(t.Category),
+	}
+
+	return trade, !row.invalid
 }
 
 type postParam struct {
@@ -458,26 +1099,59 @@ type postParam struct {
 	value string
 }
 
-func (c *client) tradeCall(command string, dest interface{}, postParams ...postParam) error {
+// Trading calls are only retried if the caller opted in with WithTradeRetryPolicy,
+// as most of them are not idempotent.
+func (c *client) tradeCall(ctx context.Context, command string, dest interface{}, postParams ...postParam) error {
+	return c.retry(ctx, c.tradeRetry, command, func() error {
+		return c.tradeCallOnce(ctx, command, dest, postParams...)
+	})
+}
+
+// tradeCallOnce sends a trading call without applying the retry policy,
+// for calls which must never be sent twice.
+func (c *client) tradeCallOnce(ctx context.Context, command string, dest interface{}, postParams ...postParam) error {
+	err := c.doTradeCall(ctx, command, dest, postParams...)
+
+	// A nonce too low is rejected before being processed,
+	// so the call can safely be sent again whatever the command is.
+	if c.resyncNonce(err) {
+		return c.doTradeCall(ctx, command, dest, postParams...)
+	}
+
+	return err
+}
+
+func (c *client) doTradeCall(ctx context.Context, command string, dest interface{}, postParams ...postParam) error {
+	if err := waitRateLimiter(ctx, c.tradeLimiter); err != nil {
+		return err
+	}
+
+	nonce, err := c.nonce.Nonce()
+	if err != nil {
+		c.logger.Errorf("unable to generate nonce: %v", err)
+
+		return err
+	}
+
 	form := url.Values{}
 	form.Set("command", string(command))
-	form.Set("nonce", fmt.Sprintf("%d", time.Now().UnixNano()))
+	form.Set("nonce", fmt.Sprintf("%d", nonce))
 
 	for _, p := range postParams {
 		form.Set(p.key, p.value)
 	}
 
 	body := form.Encode()
-	req, err := http.NewRequest("POST", TradeAPI, strings.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.tradeURL, strings.NewReader(body))
 	if err != nil {
-		logrus.WithError(err).Info("unable to create POST request")
+		c.logger.Infof("unable to create POST request: %v", err)
 
 		return err
 	}
 
 	signedBody, err := helper.HmacSha512(c.secret, body)
 	if err != nil {
-		logrus.WithError(err).Error("unable to create hash from secret and request body")
+		c.logger.Errorf("unable to create hash from secret and request body: %v", err)
 
 		return err
 	}
@@ -488,45 +1162,69 @@ func (c *client) tradeCall(command string, dest interface{}, postParams ...postP
 	req.Header.Set("Key", c.key)
 	req.Header.Set("Sign", signedBody)
 
-	return c.processRequest(req, dest)
+	return c.processRequest(req, command, dest)
+}
+
+// Poloniex returns its errors as a JSON map with an "error" key,
+// whatever the expected response of the command is.
+type errorFromJSON struct {
+	Error string `json:"error"`
 }
 
-func (c *client) processRequest(r *http.Request, dest interface{}) error {
-	resp, err := http.DefaultClient.Do(r)
+func (c *client) processRequest(r *http.Request, command string, dest interface{}) error {
+	if c.userAgent != "" {
+		r.Header.Set("User-Agent", c.userAgent)
+	}
+
+	resp, err := c.httpClient.Do(r)
 	if err != nil {
-		logrus.WithError(err).Info("unable to process request")
+		c.logger.Infof("unable to process request: %v", err)
+
+		// Prefer the context error so callers can check it with errors.Is.
+		if ctxErr := r.Context().Err(); ctxErr != nil {
+			return ctxErr
+		}
 
 		return err
 	}
 	defer resp.Body.Close()
 
-	if err := json.NewDecoder(resp.Body).Decode(dest); err != nil {
-		// TODO rethink that.
-		// When Poloniex API returns an error, the JSON is a map of string with an "error" key.
-		// We'll try to decode it as a map of string then.
-		// _, e := c.handleReturnedError(resp.Body)
-		// if e != nil {
-		// 	logrus.WithError(err).Error("unable to decode JSON")
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.logger.Infof("unable to read response body: %v", err)
 
-		// 	return e
-		// }
+		return err
+	}
+
+	// Responses which are not JSON objects (arrays for example) can't be errors,
+	// so the unmarshal error is ignored on purpose.
+	e := &errorFromJSON{}
+	if json.Unmarshal(body, e) == nil && e.Error != "" {
+		apiErr := newAPIError(resp.StatusCode, command, e.Error)
+		apiErr.fromJSON = true
 
-		// TODO we should return errorMap here, + an error?
-		logrus.WithError(err).Error("error decoder")
-		return errors.New("find the poloniex error in the returned map")
+		return apiErr
 	}
 
-	return nil
-}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newAPIError(resp.StatusCode, command, http.StatusText(resp.StatusCode))
+	}
 
-func (c *client) handleReturnedError(body io.ReadCloser) (map[string]string, error) {
-	errorMap := map[string]string{}
-	fmt.Println(body)
-	if err := json.NewDecoder(body).Decode(errorMap); err != nil {
-		logrus.WithError(err).Info("unable to decode body as an error")
+	if err := json.Unmarshal(body, dest); err != nil {
+		c.logger.Errorf("unable to decode JSON: %v", err)
 
-		return nil, err
+		// Cloudflare sometimes answers with an HTML page instead of the JSON response.
+		if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+			return &APIError{
+				StatusCode: resp.StatusCode,
+				Command:    command,
+				Message:    "unexpected HTML response",
+				Kind:       ErrorKindUnexpectedResponse,
+			}
+		}
+
+		return fmt.Errorf("poloniex: unable to decode %s response: %v", command, err)
 	}
 
-	return errorMap, nil
+	return nil
 }
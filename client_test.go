@@ -0,0 +1,800 @@
+package poloniex
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServer answers trading and public calls with canned responses, by command.
+type fakeServer struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	responses map[string][]fakeResponse
+	calls     map[string]int
+	forms     map[string][]map[string]string
+	requests  []*fakeRequest
+}
+
+// fakeRequest is a request received by a fakeServer.
+type fakeRequest struct {
+	method string
+	path   string
+	header http.Header
+	body   string
+}
+
+type fakeResponse struct {
+	status      int
+	contentType string
+	body        string
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+
+	s := &fakeServer{
+		responses: make(map[string][]fakeResponse),
+		calls:     make(map[string]int),
+		forms:     make(map[string][]map[string]string),
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("unable to read body: %v", err)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("unable to parse form: %v", err)
+		}
+
+		command := r.Form.Get("command")
+
+		form := make(map[string]string)
+		for k := range r.Form {
+			form[k] = r.Form.Get(k)
+		}
+
+		s.mu.Lock()
+		s.calls[command]++
+		s.forms[command] = append(s.forms[command], form)
+		s.requests = append(s.requests, &fakeRequest{method: r.Method, path: r.URL.Path, header: r.Header.Clone(), body: string(body)})
+
+		// The last response of a command is repeated.
+		responses := s.responses[command]
+		var resp fakeResponse
+		if len(responses) > 0 {
+			resp = responses[0]
+		}
+		if len(responses) > 1 {
+			s.responses[command] = responses[1:]
+		}
+		s.mu.Unlock()
+
+		if len(responses) == 0 {
+			t.Errorf("unexpected command %q", command)
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		contentType := resp.contentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		w.Header().Set("Content-Type", contentType)
+
+		status := resp.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(resp.body))
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// respond queues a response to command, responses are sent in order.
+func (s *fakeServer) respond(command string, status int, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responses[command] = append(s.responses[command], fakeResponse{status: status, body: body})
+}
+
+func (s *fakeServer) respondHTML(command string, status int, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responses[command] = append(s.responses[command], fakeResponse{status: status, contentType: "text/html", body: body})
+}
+
+func (s *fakeServer) callCount(command string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.calls[command]
+}
+
+// lastForm returns the parameters of the last call of command.
+func (s *fakeServer) lastForm(command string) map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	forms := s.forms[command]
+	if len(forms) == 0 {
+		return nil
+	}
+
+	return forms[len(forms)-1]
+}
+
+// lastRequest returns the last request received, whatever its command.
+func (s *fakeServer) lastRequest() *fakeRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.requests) == 0 {
+		return nil
+	}
+
+	return s.requests[len(s.requests)-1]
+}
+
+// newTestClient returns a client talking to s, without any rate limiting.
+func newTestClient(s *fakeServer, opts ...Option) Poloniex {
+	opts = append([]Option{
+		WithBaseURL(s.URL),
+		WithRateLimiter(nil),
+		WithClock(func() time.Time { return time.Date(2018, 5, 1, 12, 0, 0, 0, time.UTC) }),
+	}, opts...)
+
+	return New("key", "secret", opts...)
+}
+
+func TestGetAvailableAccountBalances(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnAvailableAccountBalances", http.StatusOK, `{"exchange":{"BTC":"1.19042859","BTM":"386.52379392"},"margin":{"BTC":"3.90015637"},"lending":[]}`)
+
+	balances, err := newTestClient(s, WithParseMode(StrictParsing)).GetAvailableAccountBalances(context.Background(), AllAccounts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(balances[ExchangeAccount]) != 2 || len(balances[MarginAccount]) != 1 {
+		t.Errorf("unexpected balances %v", balances)
+	}
+
+	if b, ok := balances[LendingAccount]; ok {
+		t.Errorf("expected no lending account, got %v", b)
+	}
+
+	if b := balances[MarginAccount][0]; b.Currency != "BTC" || !b.Amount.Equal(MustParseDecimal("3.90015637")) {
+		t.Errorf("unexpected margin balance %+v", b)
+	}
+}
+
+func TestGetAvailableAccountBalancesEmpty(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnAvailableAccountBalances", http.StatusOK, `[]`)
+
+	balances, err := newTestClient(s, WithParseMode(StrictParsing)).GetAvailableAccountBalances(context.Background(), AllAccounts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if balances == nil || len(balances) != 0 {
+		t.Errorf("expected no account, got %v", balances)
+	}
+}
+
+func TestGetAvailableAccountBalancesInvalidAccount(t *testing.T) {
+	const body = `{"exchange":{"BTC":"1.19042859"},"margin":"unavailable"}`
+
+	t.Run("strict", func(t *testing.T) {
+		s := newFakeServer(t)
+		s.respond("returnAvailableAccountBalances", http.StatusOK, body)
+
+		_, err := newTestClient(s, WithParseMode(StrictParsing)).GetAvailableAccountBalances(context.Background(), AllAccounts)
+
+		var parseErrs ParseErrors
+		if !errors.As(err, &parseErrs) || len(parseErrs) != 1 || parseErrs[0].Key != "margin" {
+			t.Fatalf("expected a parse error for the margin account, got %v", err)
+		}
+	})
+
+	t.Run("lenient", func(t *testing.T) {
+		s := newFakeServer(t)
+		s.respond("returnAvailableAccountBalances", http.StatusOK, body)
+
+		skipped := []*ParseError{}
+		c := newTestClient(s, WithSkippedRowHandler(func(e *ParseError) { skipped = append(skipped, e) }))
+
+		balances, err := c.GetAvailableAccountBalances(context.Background(), AllAccounts)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, ok := balances[MarginAccount]; ok || len(balances[ExchangeAccount]) != 1 {
+			t.Errorf("unexpected balances %v", balances)
+		}
+
+		if len(skipped) != 1 || skipped[0].Key != "margin" {
+			t.Errorf("expected the margin account to be reported, got %v", skipped)
+		}
+	})
+}
+
+// newTradeHistoryServer serves the private returnTradeHistory of a single market like Poloniex does:
+// trades within the range, most recent first, up to limit.
+func newTradeHistoryServer(t *testing.T, trades []*myTradeFromJSON) *fakeServer {
+	t.Helper()
+
+	s := &fakeServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+
+		start, _ := strconv.ParseInt(r.Form.Get("start"), 10, 64)
+		end, _ := strconv.ParseInt(r.Form.Get("end"), 10, 64)
+		limit, _ := strconv.Atoi(r.Form.Get("limit"))
+
+		page := []*myTradeFromJSON{}
+		for _, trade := range trades {
+			date, _ := parseDate(trade.Date)
+			if date.Unix() >= start && date.Unix() <= end {
+				page = append(page, trade)
+			}
+		}
+
+		sort.Slice(page, func(i, j int) bool {
+			if page[i].Date != page[j].Date {
+				return page[i].Date > page[j].Date
+			}
+
+			return page[i].GlobalTradeID > page[j].GlobalTradeID
+		})
+
+		if len(page) > limit {
+			page = page[:limit]
+		}
+
+		json.NewEncoder(w).Encode(page)
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func testTrade(id int64, date, rate string) *myTradeFromJSON {
+	return &myTradeFromJSON{
+		GlobalTradeID: id,
+		TradeID:       json.Number(strconv.FormatInt(id, 10)),
+		OrderNumber:   "1",
+		Date:          date,
+		Type:          "buy",
+		Rate:          rate,
+		Amount:        "1",
+		Total:         rate,
+		Fee:           "0.0025",
+		Category:      "exchange",
+	}
+}
+
+func TestGetMyTradeHistoryLimitWithinSameSecond(t *testing.T) {
+	// The first page contains an invalid trade, so a second page is needed,
+	// which only gets trades already received as several trades share the same second.
+	s := newTradeHistoryServer(t, []*myTradeFromJSON{
+		testTrade(7, "2018-05-01 10:00:10", "invalid"),
+		testTrade(6, "2018-05-01 10:00:05", "0.01"),
+		testTrade(5, "2018-05-01 10:00:05", "0.01"),
+		testTrade(4, "2018-05-01 10:00:05", "0.01"),
+		testTrade(3, "2018-05-01 10:00:05", "0.01"),
+		testTrade(2, "2018-05-01 10:00:00", "0.01"),
+	})
+
+	c := newTestClient(s, WithSkippedRowHandler(func(*ParseError) {}))
+
+	start := time.Date(2018, 5, 1, 0, 0, 0, 0, time.UTC)
+	trades, err := c.GetMyTradeHistory(context.Background(), "BTC_ETH", start, time.Time{}, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ids := []int64{}
+	for _, trade := range trades {
+		ids = append(ids, trade.GlobalTradeID)
+	}
+
+	if len(ids) != 3 || ids[0] != 6 || ids[1] != 5 || ids[2] != 4 {
+		t.Errorf("expected trades 6, 5 and 4, got %v", ids)
+	}
+}
+
+func TestGetMyTradeHistoryRequiresStartWithEnd(t *testing.T) {
+	s := newTradeHistoryServer(t, nil)
+
+	_, err := newTestClient(s).GetMyTradeHistory(context.Background(), "BTC_ETH", time.Time{}, time.Now(), 0)
+	if err == nil {
+		t.Fatal("expected an error with an end but no start")
+	}
+}
+
+// newHangingServer returns a server which only answers once the request is cancelled,
+// and reports every cancelled request on the returned channel.
+func newHangingServer(t *testing.T) (*fakeServer, <-chan struct{}) {
+	t.Helper()
+
+	cancelled := make(chan struct{}, 10)
+
+	s := &fakeServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server only notices a closed connection once the body is read.
+		io.ReadAll(r.Body)
+
+		select {
+		case <-r.Context().Done():
+			cancelled <- struct{}{}
+		case <-time.After(5 * time.Second):
+			t.Error("the request was never cancelled")
+		}
+	}))
+	t.Cleanup(s.Close)
+
+	return s, cancelled
+}
+
+func TestContextReachesRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(ctx context.Context, c Poloniex) error
+	}{
+		{name: "public call", call: func(ctx context.Context, c Poloniex) error {
+			_, err := c.GetCurrencies(ctx)
+			return err
+		}},
+		{name: "trading call", call: func(ctx context.Context, c Poloniex) error {
+			_, err := c.GetBalances(ctx)
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" with deadline", func(t *testing.T) {
+			s, cancelled := newHangingServer(t)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			err := tt.call(ctx, newTestClient(s, WithRetryPolicy(nil)))
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+			}
+
+			select {
+			case <-cancelled:
+			case <-time.After(time.Second):
+				t.Fatal("the server never saw the request cancelled")
+			}
+		})
+
+		t.Run(tt.name+" cancelled", func(t *testing.T) {
+			s, cancelled := newHangingServer(t)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(50*time.Millisecond, cancel)
+
+			err := tt.call(ctx, newTestClient(s, WithRetryPolicy(nil)))
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("expected context.Canceled, got %v", err)
+			}
+
+			select {
+			case <-cancelled:
+			case <-time.After(time.Second):
+				t.Fatal("the server never saw the request cancelled")
+			}
+		})
+	}
+}
+
+func TestContextCancelledDuringBackoff(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnCurrencies", http.StatusBadGateway, ``)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	c := newTestClient(s, WithRetryPolicy(&RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Minute}))
+
+	start := time.Now()
+	if _, err := c.GetCurrencies(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	if time.Since(start) > 5*time.Second {
+		t.Error("the backoff wasn't interrupted by the context")
+	}
+}
+
+func TestGetTickers(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnTicker", http.StatusOK, `{"BTC_LTC":{"id":50,"last":"0.0251","lowestAsk":"0.02589999","highestBid":"0.0251","percentChange":"0.02390438","baseVolume":"6.16485315","quoteVolume":"245.82513926","isFrozen":"0","high24hr":"0.02600000","low24hr":"0.02410000"},"BTC_NXT":{"id":69,"last":"0.00005730","lowestAsk":"0.00005710","highestBid":"0.00004903","percentChange":"0.16701570","baseVolume":"0.45347489","quoteVolume":"9094","isFrozen":"1","high24hr":"0.00005800","low24hr":"0.00004800"}}`)
+
+	tickers, err := newTestClient(s, WithParseMode(StrictParsing)).GetTickers(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(tickers) != 2 {
+		t.Fatalf("expected 2 tickers, got %d", len(tickers))
+	}
+
+	for _, ticker := range tickers {
+		switch ticker.Pair {
+		case "BTC_LTC":
+			if ticker.ID != 50 || !ticker.Last.Equal(MustParseDecimal("0.0251")) || !ticker.LowestAsk.Equal(MustParseDecimal("0.02589999")) || ticker.IsFrozen {
+				t.Errorf("unexpected ticker %+v", ticker)
+			}
+		case "BTC_NXT":
+			if !ticker.IsFrozen || !ticker.QuoteVolume.Equal(MustParseDecimal("9094")) {
+				t.Errorf("unexpected ticker %+v", ticker)
+			}
+		default:
+			t.Errorf("unexpected pair %s", ticker.Pair)
+		}
+	}
+}
+
+func TestGetTradeHistory(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnTradeHistory", http.StatusOK, `[{"globalTradeID":394127362,"tradeID":13536351,"date":"2018-05-01 10:00:00","type":"buy","rate":"0.01","amount":"0.5","total":"0.005"},{"globalTradeID":394127361,"tradeID":13536350,"date":"2018-05-01 09:59:59","type":"sell","rate":"0.0099","amount":"1","total":"0.0099"}]`)
+
+	start := time.Date(2018, 5, 1, 9, 0, 0, 0, time.UTC)
+	end := time.Date(2018, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	trades, err := newTestClient(s, WithParseMode(StrictParsing)).GetTradeHistory(context.Background(), "BTC_ETH", start, end)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if form := s.lastForm("returnTradeHistory"); form["start"] != "1525165200" || form["end"] != "1525168800" {
+		t.Errorf("unexpected parameters %v", form)
+	}
+
+	if len(trades) != 2 || trades[0].TradeID != 13536351 || trades[0].Type != BuySide || trades[1].Type != SellSide ||
+		!trades[0].Date.Equal(end) || !trades[1].Total.Equal(MustParseDecimal("0.0099")) {
+		t.Errorf("unexpected trades %v", trades)
+	}
+}
+
+func TestGetDepositAddresses(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnDepositAddresses", http.StatusOK, `{"BTC":"19YqztHmspv2egyD6jQM3yn81x5t5krVdJ","LTC":"LPgf9kjv9H1Vuh4XSaKhzBe8JHdou1WgUB"}`)
+
+	s.respond("returnDepositAddresses", http.StatusOK, `[]`)
+
+	c := newTestClient(s)
+
+	addresses, err := c.GetDepositAddresses(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(addresses) != 2 || addresses["BTC"] != "19YqztHmspv2egyD6jQM3yn81x5t5krVdJ" {
+		t.Errorf("unexpected addresses %v", addresses)
+	}
+
+	addresses, err = c.GetDepositAddresses(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error without any address: %v", err)
+	}
+
+	if addresses == nil || len(addresses) != 0 {
+		t.Errorf("expected no address, got %v", addresses)
+	}
+}
+
+func TestGenerateNewAddress(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("generateNewAddress", http.StatusOK, `{"success":1,"response":"CKXbbs8FAVbtEa397gJHSutmrdrBrhUMxe"}`)
+	s.respond("generateNewAddress", http.StatusOK, `{"success":0,"response":"You have already generated a new address in the past 24 hours."}`)
+
+	c := newTestClient(s)
+
+	g, err := c.GenerateNewAddress(context.Background(), "BTC")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !g.Generated || g.Address != "CKXbbs8FAVbtEa397gJHSutmrdrBrhUMxe" || g.Currency != "BTC" {
+		t.Errorf("unexpected result %+v", g)
+	}
+
+	g, err = c.GenerateNewAddress(context.Background(), "BTC")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if g.Generated || g.Address != "" || g.Response == "" {
+		t.Errorf("unexpected result %+v", g)
+	}
+}
+
+func TestGetDepositsWithdrawals(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnDepositsWithdrawals", http.StatusOK, `{"deposits":[{"currency":"BTC","address":"btc-address","amount":"0.01006132","confirmations":10,"txid":"17f819a91369a9ff6c4a34216d434597cfc1b4a3d0489b46bd6f924137a47701","timestamp":1399305798,"status":"COMPLETE"}],"withdrawals":[{"withdrawalNumber":134933,"currency":"BTC","address":"1N2i5n8DwTGzUq2Vmn9TUL8J1vdr1XBDFg","amount":"5.00010000","fee":"0.00010000","timestamp":1399267904,"status":"COMPLETE: 36e483efa6aff9fd53a235177579d98451c4eb237c210e66cd2b9a2d4a988f8e","ipAddress":"127.0.0.1"}]}`)
+	s.respond("returnDepositsWithdrawals", http.StatusOK, `{"deposits":[],"withdrawals":[]}`)
+
+	start := time.Date(2014, 5, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(45 * 24 * time.Hour)
+
+	d, err := newTestClient(s, WithParseMode(StrictParsing)).GetDepositsWithdrawals(context.Background(), start, end)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 45 days are fetched in two windows.
+	if n := s.callCount("returnDepositsWithdrawals"); n != 2 {
+		t.Errorf("expected 2 calls, got %d", n)
+	}
+
+	if form := s.lastForm("returnDepositsWithdrawals"); form["start"] != "1401494400" || form["end"] != "1402790400" {
+		t.Errorf("unexpected parameters of the last window %v", form)
+	}
+
+	if len(d.Deposits) != 1 || len(d.Withdrawals) != 1 {
+		t.Fatalf("unexpected result %+v", d)
+	}
+
+	if dep := d.Deposits[0]; !dep.Amount.Equal(MustParseDecimal("0.01006132")) || dep.Confirmations != 10 || !dep.Date.Equal(time.Unix(1399305798, 0)) {
+		t.Errorf("unexpected deposit %+v", dep)
+	}
+
+	w := d.Withdrawals[0]
+	if w.Status != "COMPLETE" || w.TxID != "36e483efa6aff9fd53a235177579d98451c4eb237c210e66cd2b9a2d4a988f8e" || !w.Fee.Equal(MustParseDecimal("0.0001")) {
+		t.Errorf("unexpected withdrawal %+v", w)
+	}
+}
+
+func TestGetAllOpenOrders(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnOpenOrders", http.StatusOK, `{"BTC_ETH":[{"orderNumber":"120466","type":"sell","rate":"0.025","amount":"100","startingAmount":"120","total":"2.5","date":"2018-05-01 10:00:00","margin":1}],"BTC_LTC":[],"BTC_NXT":[{"orderNumber":"120467","type":"buy","rate":"0.00001","amount":"1000","total":"0.01","date":"2018-05-01 11:00:00","margin":0}]}`)
+
+	orders, err := newTestClient(s, WithParseMode(StrictParsing)).GetAllOpenOrders(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(orders) != 2 {
+		t.Fatalf("expected 2 orders, got %d", len(orders))
+	}
+
+	for _, o := range orders {
+		switch o.OrderNumber {
+		case 120466:
+			if o.Pair != "BTC_ETH" || o.Type != SellSide || !o.Margin || !o.StartingAmount.Equal(MustParseDecimal("120")) {
+				t.Errorf("unexpected order %+v", o)
+			}
+		case 120467:
+			if o.Pair != "BTC_NXT" || o.Margin || !o.StartingAmount.IsZero() {
+				t.Errorf("unexpected order %+v", o)
+			}
+		default:
+			t.Errorf("unexpected order number %d", o.OrderNumber)
+		}
+	}
+}
+
+func TestGetAllOpenOrdersEmpty(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnOpenOrders", http.StatusOK, `[]`)
+
+	orders, err := newTestClient(s, WithParseMode(StrictParsing)).GetAllOpenOrders(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if orders == nil || len(orders) != 0 {
+		t.Errorf("expected no order, got %v", orders)
+	}
+}
+
+func TestGetMyTradeHistoryAllPairs(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnTradeHistory", http.StatusOK, `{"BTC_ETH":[{"globalTradeID":25129732,"tradeID":"6325758","date":"2016-04-05 08:08:40","rate":"0.02565498","amount":"0.10000000","total":"0.00256549","fee":"0.00200000","orderNumber":"34225313575","type":"sell","category":"exchange"}],"BTC_FCT":[{"globalTradeID":25129628,"tradeID":1275374,"date":"2016-04-05 08:05:04","rate":"0.00295000","amount":"12.00000000","total":"0.03540000","fee":"0.00250000","orderNumber":"35011234568","type":"buy","category":"marginTrade"}]}`)
+
+	trades, err := newTestClient(s, WithParseMode(StrictParsing)).GetMyTradeHistory(context.Background(), AllPairs, time.Time{}, time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(trades) != 2 || trades[0].GlobalTradeID != 25129732 || trades[1].GlobalTradeID != 25129628 {
+		t.Fatalf("expected trades most recent first, got %v", trades)
+	}
+
+	if tr := trades[1]; tr.Pair != "BTC_FCT" || tr.TradeID != 1275374 || tr.OrderNumber != 35011234568 || tr.Category != "marginTrade" || !tr.Fee.Equal(MustParseDecimal("0.0025")) {
+		t.Errorf("unexpected trade %+v", tr)
+	}
+
+	if form := s.lastForm("returnTradeHistory"); form["currencyPair"] != "all" || form["start"] != "" {
+		t.Errorf("unexpected parameters %v", form)
+	}
+}
+
+func TestGetMyTradeHistoryAllPairsEmpty(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnTradeHistory", http.StatusOK, `[]`)
+
+	start := time.Date(2018, 4, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2018, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	trades, err := newTestClient(s, WithParseMode(StrictParsing)).GetMyTradeHistory(context.Background(), AllPairs, start, end, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if trades == nil || len(trades) != 0 {
+		t.Errorf("expected no trade, got %v", trades)
+	}
+}
+
+func TestTransferBalance(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("transferBalance", http.StatusOK, `{"success":1,"message":"Transferred 2 BTC from exchange to margin account."}`)
+	s.respond("transferBalance", http.StatusOK, `{"success":0,"message":"Not enough BTC."}`)
+
+	c := newTestClient(s)
+
+	r, err := c.TransferBalance(context.Background(), "BTC", MustParseDecimal("2"), ExchangeAccount, MarginAccount)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if r.Message != "Transferred 2 BTC from exchange to margin account." {
+		t.Errorf("unexpected message %q", r.Message)
+	}
+
+	if form := s.lastForm("transferBalance"); form["fromAccount"] != "exchange" || form["toAccount"] != "margin" || form["amount"] != "2" {
+		t.Errorf("unexpected parameters %v", form)
+	}
+
+	_, err = c.TransferBalance(context.Background(), "BTC", MustParseDecimal("2"), ExchangeAccount, MarginAccount)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Kind != ErrorKindInsufficientFunds {
+		t.Fatalf("expected an insufficient funds APIError, got %v", err)
+	}
+
+	if _, err := c.TransferBalance(context.Background(), "BTC", MustParseDecimal("2"), MarginAccount, MarginAccount); err == nil {
+		t.Error("expected an error when transferring to the same account")
+	}
+}
+
+func TestGet24hVolume(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("return24hVolume", http.StatusOK, `{"BTC_LTC":{"BTC":"2.23248854","LTC":"87.10381314"},"BTC_NXT":{"BTC":"0.981616","NXT":"14145"},"totalBTC":"81.89657704","totalLTC":"78.52083806"}`)
+
+	volume, err := newTestClient(s).Get24hVolume(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(volume.Markets) != 2 || !volume.Markets["BTC_LTC"]["LTC"].Equal(MustParseDecimal("87.10381314")) {
+		t.Errorf("unexpected markets %v", volume.Markets)
+	}
+
+	if len(volume.PrimaryCurrenciesTotals) != 2 || !volume.PrimaryCurrenciesTotals["totalBTC"].Equal(MustParseDecimal("81.89657704")) {
+		t.Errorf("unexpected totals %v", volume.PrimaryCurrenciesTotals)
+	}
+}
+
+func TestGetOrderBook(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnOrderBook", http.StatusOK, `{"asks":[["0.00007600",1164],["0.00007620",1300]],"bids":[["0.00006901",200],["0.00006900",408]],"isFrozen":"0","seq":18849}`)
+
+	orderBook, err := newTestClient(s, WithParseMode(StrictParsing)).GetOrderBook(context.Background(), "BTC_NXT", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if form := s.lastForm("returnOrderBook"); form["currencyPair"] != "BTC_NXT" || form["depth"] != "2" {
+		t.Errorf("unexpected parameters %v", form)
+	}
+
+	if orderBook.Pair != "BTC_NXT" || orderBook.Seq != 18849 || len(orderBook.Asks) != 2 || len(orderBook.Bids) != 2 {
+		t.Fatalf("unexpected order book %+v", orderBook)
+	}
+
+	if ask := orderBook.Asks[0]; !ask.Value.Equal(MustParseDecimal("0.000076")) || !ask.Amount.Equal(MustParseDecimal("1164")) {
+		t.Errorf("unexpected ask %+v", ask)
+	}
+}
+
+func TestGetAllOrderBooks(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnOrderBook", http.StatusOK, `{"BTC_NXT":{"asks":[["0.00007600",1164]],"bids":[["0.00006901",200]],"isFrozen":"0","seq":149},"BTC_XMR":{"asks":[["0.00369300",0.5]],"bids":[["0.00369000",1.2]],"isFrozen":"0","seq":3}}`)
+
+	orderBooks, err := newTestClient(s, WithParseMode(StrictParsing)).GetAllOrderBooks(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(orderBooks) != 2 {
+		t.Fatalf("expected 2 order books, got %d", len(orderBooks))
+	}
+
+	if form := s.lastForm("returnOrderBook"); form["currencyPair"] != "all" {
+		t.Errorf("unexpected parameters %v", form)
+	}
+}
+
+func TestGetLoanOrders(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnLoanOrders", http.StatusOK, `{"offers":[{"rate":"0.00200000","amount":"64.66305732","rangeMin":2,"rangeMax":8}],"demands":[{"rate":"0.00170000","amount":"26.54848841","rangeMin":2,"rangeMax":2}]}`)
+
+	loanOrders, err := newTestClient(s, WithParseMode(StrictParsing)).GetLoanOrders(context.Background(), "BTC")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(loanOrders.Offers) != 1 || len(loanOrders.Demands) != 1 {
+		t.Fatalf("unexpected loan orders %+v", loanOrders)
+	}
+
+	if o := loanOrders.Offers[0]; !o.Rate.Equal(MustParseDecimal("0.002")) || !o.Amount.Equal(MustParseDecimal("64.66305732")) || o.RangeMin != 2 || o.RangeMax != 8 {
+		t.Errorf("unexpected offer %+v", o)
+	}
+}
+
+func TestGetBalances(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnBalances", http.StatusOK, `{"BTC":"0.59098578","LTC":"3.31117268"}`)
+
+	balances, err := newTestClient(s, WithParseMode(StrictParsing)).GetBalances(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(balances) != 2 {
+		t.Fatalf("expected 2 balances, got %d", len(balances))
+	}
+
+	for _, b := range balances {
+		if b.Currency == "BTC" && !b.Amount.Equal(MustParseDecimal("0.59098578")) {
+			t.Errorf("unexpected balance %+v", b)
+		}
+	}
+}
+
+func TestGetCompleteBalances(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnCompleteBalances", http.StatusOK, `{"LTC":{"available":"5.015","onOrders":"1.0025","btcValue":"0.078"}}`)
+
+	balances, err := newTestClient(s, WithParseMode(StrictParsing)).GetCompleteBalances(context.Background(), AllAccounts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if form := s.lastForm("returnCompleteBalances"); form["account"] != string(AllAccounts) {
+		t.Errorf("unexpected parameters %v", form)
+	}
+
+	if len(balances) != 1 || balances[0].Currency != "LTC" || !balances[0].Available.Equal(MustParseDecimal("5.015")) ||
+		!balances[0].OnOrders.Equal(MustParseDecimal("1.0025")) || !balances[0].BTCValue.Equal(MustParseDecimal("0.078")) {
+		t.Errorf("unexpected balances %v", balances)
+	}
+}
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"context"
+	"os"
+
 	"github.com/Charrette/poloniex"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/sirupsen/logrus"
@@ -8,7 +11,7 @@ import (
 
 func main() {
 
-	p := poloniex.New()
+	p := poloniex.New(os.Getenv("POLONIEX_API_KEY"), os.Getenv("POLONIEX_API_SECRET"))
 
 	// volume, err := p.Get24hVolume()
 	// if err != nil {
@@ -67,7 +70,7 @@ func main() {
 
 	// spew.Dump(balances)
 
-	completeBalances, err := p.GetCompleteBalances(poloniex.AllAccounts)
+	completeBalances, err := p.GetCompleteBalances(context.Background(), poloniex.AllAccounts)
 	if err != nil {
 		logrus.Fatal(err)
 	}
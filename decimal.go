@@ -0,0 +1,311 @@
+package poloniex
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// CurrencyPrecision is the number of decimal places used by Poloniex for prices, amounts and balances.
+const CurrencyPrecision = 8
+
+// Decimal is an exact decimal number.
+// Poloniex returns prices, amounts and balances as decimal strings,
+// which are parsed into Decimal without any loss, unlike float64.
+// The zero value is 0. Decimal values are immutable, every operation returns a new Decimal.
+type Decimal struct {
+	// The value is unscaled / 10^scale.
+	unscaled *big.Int
+	scale    int32
+}
+
+// NewDecimal returns the Decimal value * 10^exp.
+// Ex: NewDecimal(25, -4) == 0.0025
+func NewDecimal(value int64, exp int32) Decimal {
+	d := Decimal{unscaled: big.NewInt(value)}
+	if exp >= 0 {
+		d.unscaled.Mul(d.unscaled, pow10(exp))
+
+		return d
+	}
+
+	d.scale = -exp
+
+	return d
+}
+
+// NewDecimalFromFloat converts f to a Decimal, using the shortest representation of f.
+func NewDecimalFromFloat(f float64) (Decimal, error) {
+	return ParseDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// Exponents are bounded so that a malformed response can't make us allocate huge numbers.
+const maxDecimalExponent = 1000
+
+// ParseDecimal parses a decimal number, like "0.00012345", "-12" or "1.5e-8".
+func ParseDecimal(s string) (Decimal, error) {
+	original := s
+
+	var exp int64
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.ParseInt(s[i+1:], 10, 32)
+		if err != nil || e > maxDecimalExponent || e < -maxDecimalExponent {
+			return Decimal{}, fmt.Errorf("poloniex: invalid decimal %q", original)
+		}
+
+		exp = e
+		s = s[:i]
+	}
+
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+
+	digits := strings.TrimLeft(intPart, "+-")
+	if len(intPart)-len(digits) > 1 || (digits == "" && fracPart == "") || !isDigits(digits) || !isDigits(fracPart) {
+		return Decimal{}, fmt.Errorf("poloniex: invalid decimal %q", original)
+	}
+
+	unscaled, ok := new(big.Int).SetString(intPart+fracPart, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("poloniex: invalid decimal %q", original)
+	}
+
+	scale := int64(len(fracPart)) - exp
+	if scale < 0 {
+		unscaled.Mul(unscaled, pow10(int32(-scale)))
+		scale = 0
+	}
+
+	return Decimal{unscaled: unscaled, scale: int32(scale)}, nil
+}
+
+// MustParseDecimal is like ParseDecimal but panics if s can't be parsed.
+// It's meant to initialize constants.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func (d Decimal) value() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+
+	return d.unscaled
+}
+
+// rescale returns the unscaled value of d with the given scale, which must not be lower than d.scale.
+func (d Decimal) rescale(scale int32) *big.Int {
+	v := new(big.Int).Set(d.value())
+	if scale > d.scale {
+		v.Mul(v, pow10(scale-d.scale))
+	}
+
+	return v
+}
+
+func maxScale(a, b Decimal) int32 {
+	if a.scale > b.scale {
+		return a.scale
+	}
+
+	return b.scale
+}
+
+// Add returns d + d2.
+func (d Decimal) Add(d2 Decimal) Decimal {
+	scale := maxScale(d, d2)
+
+	return Decimal{unscaled: new(big.Int).Add(d.rescale(scale), d2.rescale(scale)), scale: scale}
+}
+
+// Sub returns d - d2.
+func (d Decimal) Sub(d2 Decimal) Decimal {
+	scale := maxScale(d, d2)
+
+	return Decimal{unscaled: new(big.Int).Sub(d.rescale(scale), d2.rescale(scale)), scale: scale}
+}
+
+// Mul returns d * d2.
+func (d Decimal) Mul(d2 Decimal) Decimal {
+	return Decimal{unscaled: new(big.Int).Mul(d.value(), d2.value()), scale: d.scale + d2.scale}
+}
+
+// Div returns d / d2 rounded to places decimal places, which can be negative like for Round.
+// It panics if d2 is zero.
+func (d Decimal) Div(d2 Decimal, places int32) Decimal {
+	if d2.IsZero() {
+		panic("poloniex: decimal division by zero")
+	}
+
+	// Compute with one more place than needed, then round.
+	// d / d2 = (d.unscaled * 10^(places+1+d2.scale-d.scale)) / d2.unscaled / 10^(places+1)
+	num := new(big.Int).Set(d.value())
+	shift := places + 1 + d2.scale - d.scale
+	if shift >= 0 {
+		num.Mul(num, pow10(shift))
+	} else {
+		num.Quo(num, pow10(-shift))
+	}
+
+	return Decimal{unscaled: num.Quo(num, d2.value()), scale: places + 1}.Round(places)
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	return Decimal{unscaled: new(big.Int).Neg(d.value()), scale: d.scale}
+}
+
+// Abs returns the absolute value of d.
+func (d Decimal) Abs() Decimal {
+	return Decimal{unscaled: new(big.Int).Abs(d.value()), scale: d.scale}
+}
+
+// Round rounds d to places decimal places, half away from zero.
+// Use CurrencyPrecision to round to the precision of Poloniex.
+// A negative places rounds to tens, hundreds and so on, ex: 123.456 rounded to -1 places is 120.
+func (d Decimal) Round(places int32) Decimal {
+	if places >= d.scale {
+		return d
+	}
+
+	factor := pow10(d.scale - places)
+	q, r := new(big.Int).QuoRem(d.value(), factor, new(big.Int))
+
+	// Round away from zero when the remainder is at least half of the factor.
+	r.Abs(r).Mul(r, big.NewInt(2))
+	if r.Cmp(factor) >= 0 {
+		q.Add(q, big.NewInt(int64(d.Sign())))
+	}
+
+	return scaledDecimal(q, places)
+}
+
+// Truncate drops the decimal places of d after places, without rounding.
+// Like Round, places can be negative.
+func (d Decimal) Truncate(places int32) Decimal {
+	if places >= d.scale {
+		return d
+	}
+
+	return scaledDecimal(new(big.Int).Quo(d.value(), pow10(d.scale-places)), places)
+}
+
+// scaledDecimal returns unscaled / 10^scale.
+// A negative scale, from rounding to tens for example, is applied to unscaled so that scale is never negative.
+func scaledDecimal(unscaled *big.Int, scale int32) Decimal {
+	if scale < 0 {
+		return Decimal{unscaled: unscaled.Mul(unscaled, pow10(-scale))}
+	}
+
+	return Decimal{unscaled: unscaled, scale: scale}
+}
+
+// Cmp compares d and d2 and returns -1 if d < d2, 0 if d == d2 and +1 if d > d2.
+func (d Decimal) Cmp(d2 Decimal) int {
+	scale := maxScale(d, d2)
+
+	return d.rescale(scale).Cmp(d2.rescale(scale))
+}
+
+// Equal reports whether d == d2, whatever their number of decimal places is.
+func (d Decimal) Equal(d2 Decimal) bool {
+	return d.Cmp(d2) == 0
+}
+
+// LessThan reports whether d < d2.
+func (d Decimal) LessThan(d2 Decimal) bool {
+	return d.Cmp(d2) < 0
+}
+
+// GreaterThan reports whether d > d2.
+func (d Decimal) GreaterThan(d2 Decimal) bool {
+	return d.Cmp(d2) > 0
+}
+
+// Sign returns -1 if d < 0, 0 if d == 0 and +1 if d > 0.
+func (d Decimal) Sign() int {
+	return d.value().Sign()
+}
+
+// IsZero reports whether d == 0.
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Float64 returns the nearest float64 value of d.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+
+	return f
+}
+
+// String returns d with all its decimal places, ex: "0.00012300".
+func (d Decimal) String() string {
+	s := new(big.Int).Abs(d.value()).String()
+
+	if d.scale > 0 {
+		if len(s) <= int(d.scale) {
+			s = strings.Repeat("0", int(d.scale)-len(s)+1) + s
+		}
+
+		s = s[:len(s)-int(d.scale)] + "." + s[len(s)-int(d.scale):]
+	}
+
+	if d.Sign() < 0 {
+		s = "-" + s
+	}
+
+	return s
+}
+
+// MarshalJSON encodes d as a JSON string, the same way Poloniex does.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+// UnmarshalJSON decodes d from either a JSON string or a JSON number.
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if string(b) == "null" {
+		return nil
+	}
+
+	s := string(b)
+	if len(b) > 0 && b[0] == '"' {
+		var err error
+		if s, err = strconv.Unquote(s); err != nil {
+			return fmt.Errorf("poloniex: invalid decimal %s", b)
+		}
+	}
+
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package poloniex
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		fails    bool
+	}{
+		{input: "0.00012345", expected: "0.00012345"},
+		{input: "-12", expected: "-12"},
+		{input: "+12.5", expected: "12.5"},
+		{input: "1.5e-8", expected: "0.000000015"},
+		{input: "1.5E3", expected: "1500"},
+		{input: ".5", expected: "0.5"},
+		{input: "5.", expected: "5"},
+		{input: "0", expected: "0"},
+		{input: "-0.00000001", expected: "-0.00000001"},
+		{input: "123456789012345678901234567890.123456789", expected: "123456789012345678901234567890.123456789"},
+		{input: "", fails: true},
+		{input: ".", fails: true},
+		{input: "-", fails: true},
+		{input: "--1", fails: true},
+		{input: "1.2.3", fails: true},
+		{input: "abc", fails: true},
+		{input: "1e", fails: true},
+		{input: "1e100000", fails: true},
+		{input: " 1", fails: true},
+	}
+
+	for _, tt := range tests {
+		d, err := ParseDecimal(tt.input)
+		if tt.fails {
+			if err == nil {
+				t.Errorf("ParseDecimal(%q): expected an error, got %s", tt.input, d)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("ParseDecimal(%q): unexpected error: %v", tt.input, err)
+
+			continue
+		}
+
+		if d.String() != tt.expected {
+			t.Errorf("ParseDecimal(%q) = %s, expected %s", tt.input, d, tt.expected)
+		}
+	}
+}
+
+func TestDecimalRound(t *testing.T) {
+	tests := []struct {
+		value    string
+		places   int32
+		expected string
+	}{
+		{value: "0.123456789", places: 8, expected: "0.12345679"},
+		{value: "0.123456784", places: 8, expected: "0.12345678"},
+		{value: "0.5", places: 0, expected: "1"},
+		{value: "-0.5", places: 0, expected: "-1"},
+		{value: "-0.49", places: 0, expected: "0"},
+		{value: "1.25", places: 1, expected: "1.3"},
+		{value: "1.2", places: 4, expected: "1.2"},
+		{value: "123.456", places: -1, expected: "120"},
+		{value: "125", places: -1, expected: "130"},
+		{value: "-125", places: -1, expected: "-130"},
+		{value: "123.456", places: -3, expected: "0"},
+		{value: "987.6", places: -2, expected: "1000"},
+	}
+
+	for _, tt := range tests {
+		d := MustParseDecimal(tt.value).Round(tt.places)
+		if d.String() != tt.expected {
+			t.Errorf("%s.Round(%d) = %s, expected %s", tt.value, tt.places, d, tt.expected)
+		}
+	}
+}
+
+func TestDecimalTruncate(t *testing.T) {
+	tests := []struct {
+		value    string
+		places   int32
+		expected string
+	}{
+		{value: "0.123456789", places: 8, expected: "0.12345678"},
+		{value: "-0.123456789", places: 8, expected: "-0.12345678"},
+		{value: "1.9", places: 0, expected: "1"},
+		{value: "1.2", places: 4, expected: "1.2"},
+		{value: "129.9", places: -1, expected: "120"},
+		{value: "-129.9", places: -1, expected: "-120"},
+	}
+
+	for _, tt := range tests {
+		d := MustParseDecimal(tt.value).Truncate(tt.places)
+		if d.String() != tt.expected {
+			t.Errorf("%s.Truncate(%d) = %s, expected %s", tt.value, tt.places, d, tt.expected)
+		}
+	}
+}
+
+func TestDecimalDiv(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		places   int32
+		expected string
+	}{
+		{a: "1", b: "3", places: 8, expected: "0.33333333"},
+		{a: "2", b: "3", places: 8, expected: "0.66666667"},
+		{a: "-2", b: "3", places: 8, expected: "-0.66666667"},
+		{a: "0.0001", b: "0.00000003", places: 2, expected: "3333.33"},
+		{a: "1234.5", b: "0.5", places: 0, expected: "2469"},
+		{a: "1234.5", b: "1", places: -2, expected: "1200"},
+		{a: "10", b: "4", places: 1, expected: "2.5"},
+	}
+
+	for _, tt := range tests {
+		d := MustParseDecimal(tt.a).Div(MustParseDecimal(tt.b), tt.places)
+		if d.String() != tt.expected {
+			t.Errorf("%s.Div(%s, %d) = %s, expected %s", tt.a, tt.b, tt.places, d, tt.expected)
+		}
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	a, b := MustParseDecimal("0.1"), MustParseDecimal("0.2")
+
+	if s := a.Add(b); !s.Equal(MustParseDecimal("0.3")) {
+		t.Errorf("0.1 + 0.2 = %s", s)
+	}
+
+	if s := a.Sub(b); s.String() != "-0.1" {
+		t.Errorf("0.1 - 0.2 = %s", s)
+	}
+
+	if m := a.Mul(b); m.String() != "0.02" {
+		t.Errorf("0.1 * 0.2 = %s", m)
+	}
+
+	if !MustParseDecimal("1.10").Equal(MustParseDecimal("1.1")) || !a.LessThan(b) || a.GreaterThan(b) {
+		t.Error("unexpected comparison results")
+	}
+
+	var zero Decimal
+	if !zero.IsZero() || zero.String() != "0" || !zero.Add(a).Equal(a) {
+		t.Error("unexpected zero value")
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	var v struct {
+		String Decimal `json:"string"`
+		Number Decimal `json:"number"`
+		Null   Decimal `json:"null"`
+	}
+
+	if err := json.Unmarshal([]byte(`{"string":"0.00012300","number":1.5e-8,"null":null}`), &v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if v.String.String() != "0.00012300" || v.Number.String() != "0.000000015" || !v.Null.IsZero() {
+		t.Errorf("unexpected values %+v", v)
+	}
+
+	b, err := json.Marshal(v.String)
+	if err != nil || string(b) != `"0.00012300"` {
+		t.Errorf("unexpected encoding %s, %v", b, err)
+	}
+
+	if err := json.Unmarshal([]byte(`{"string":"abc"}`), &v); err == nil {
+		t.Error("expected an error for an invalid decimal")
+	}
+}
@@ -0,0 +1,114 @@
+package poloniex
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrorKind classifies the errors returned by Poloniex API.
+type ErrorKind string
+
+// Possible ErrorKind values.
+const (
+	ErrorKindUnknown           ErrorKind = "unknown"
+	ErrorKindInvalidKey        ErrorKind = "invalid_key"
+	ErrorKindNonceTooLow       ErrorKind = "nonce_too_low"
+	ErrorKindInsufficientFunds ErrorKind = "insufficient_funds"
+	ErrorKindRateLimited       ErrorKind = "rate_limited"
+	ErrorKindMarketFrozen      ErrorKind = "market_frozen"
+	ErrorKindUnknownPair       ErrorKind = "unknown_pair"
+
+	// The order doesn't exist, is already filled or cancelled, or was placed by someone else.
+	ErrorKindOrderNotFound ErrorKind = "order_not_found"
+
+	// The response is not the JSON expected, an HTML error page for example.
+	ErrorKindUnexpectedResponse ErrorKind = "unexpected_response"
+)
+
+// APIError is returned whenever Poloniex answers with an error,
+// either as a JSON map with an "error" key or as a non successful HTTP status.
+// Use errors.As to retrieve it from an error returned by the client.
+type APIError struct {
+	// HTTP status code of the response.
+	StatusCode int
+
+	// Command sent to Poloniex, ex: "returnOrderBook".
+	Command string
+
+	// Raw message returned by Poloniex.
+	Message string
+
+	// Kind is the classification of Message.
+	Kind ErrorKind
+
+	// Whether Message was sent by Poloniex in a JSON error,
+	// rather than derived from the HTTP status or the content of the response.
+	fromJSON bool
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("poloniex: %s failed with status %d: %s", e.Command, e.StatusCode, e.Message)
+}
+
+// Poloniex doesn't return any error code, only messages.
+// So the kind of an error is guessed from the content of its message.
+// Patterns are lower cased and checked in order.
+var errorKindPatterns = []struct {
+	pattern string
+	kind    ErrorKind
+}{
+	{pattern: "invalid api key", kind: ErrorKindInvalidKey},
+	{pattern: "invalid key", kind: ErrorKindInvalidKey},
+	{pattern: "nonce must be greater than", kind: ErrorKindNonceTooLow},
+	{pattern: "not enough", kind: ErrorKindInsufficientFunds},
+	{pattern: "insufficient", kind: ErrorKindInsufficientFunds},
+	{pattern: "too many requests", kind: ErrorKindRateLimited},
+	{pattern: "rate limit", kind: ErrorKindRateLimited},
+	// Ex: "Please do not make more than 8 API calls per second."
+	{pattern: "api calls per second", kind: ErrorKindRateLimited},
+	{pattern: "market is frozen", kind: ErrorKindMarketFrozen},
+	{pattern: "market is disabled", kind: ErrorKindMarketFrozen},
+	{pattern: "invalid currency pair", kind: ErrorKindUnknownPair},
+	{pattern: "invalid currencypair", kind: ErrorKindUnknownPair},
+	{pattern: "invalid order number", kind: ErrorKindOrderNotFound},
+	{pattern: "order not found", kind: ErrorKindOrderNotFound},
+}
+
+func classifyError(statusCode int, message string) ErrorKind {
+	m := strings.ToLower(message)
+	for _, p := range errorKindPatterns {
+		if strings.Contains(m, p.pattern) {
+			return p.kind
+		}
+	}
+
+	// Poloniex doesn't always send a message when throttling.
+	if statusCode == http.StatusTooManyRequests {
+		return ErrorKindRateLimited
+	}
+
+	return ErrorKindUnknown
+}
+
+func newAPIError(statusCode int, command, message string) *APIError {
+	return &APIError{
+		StatusCode: statusCode,
+		Command:    command,
+		Message:    message,
+		Kind:       classifyError(statusCode, message),
+	}
+}
+
+// isRejection reports whether err is Poloniex explicitly refusing a call,
+// which means the call had no effect.
+// 5xx responses and unexpected responses are not, as the call may have been processed anyway.
+func isRejection(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	return apiErr.fromJSON && apiErr.StatusCode < 500 && apiErr.Kind != ErrorKindUnexpectedResponse
+}
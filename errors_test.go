@@ -0,0 +1,77 @@
+package poloniex
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		status  int
+		message string
+		kind    ErrorKind
+	}{
+		{status: http.StatusForbidden, message: "Invalid API key/secret pair.", kind: ErrorKindInvalidKey},
+		{status: http.StatusForbidden, message: "Nonce must be greater than 1525012345678. You provided 1525012345000.", kind: ErrorKindNonceTooLow},
+		{status: http.StatusOK, message: "Not enough BTC.", kind: ErrorKindInsufficientFunds},
+		{status: http.StatusTooManyRequests, message: "", kind: ErrorKindRateLimited},
+		{status: http.StatusOK, message: "Please do not make more than 8 API calls per second.", kind: ErrorKindRateLimited},
+		{status: http.StatusOK, message: "Too many requests.", kind: ErrorKindRateLimited},
+		{status: http.StatusOK, message: "This market is frozen.", kind: ErrorKindMarketFrozen},
+		{status: http.StatusOK, message: "Invalid currency pair.", kind: ErrorKindUnknownPair},
+		{status: http.StatusOK, message: "Invalid order number, or you are not the person who placed the order.", kind: ErrorKindOrderNotFound},
+		{status: http.StatusOK, message: "Something else.", kind: ErrorKindUnknown},
+	}
+
+	for _, tt := range tests {
+		if kind := classifyError(tt.status, tt.message); kind != tt.kind {
+			t.Errorf("classifyError(%d, %q) = %s, expected %s", tt.status, tt.message, kind, tt.kind)
+		}
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		html      bool
+		message   string
+		kind      ErrorKind
+		rejection bool
+	}{
+		{name: "JSON error", status: http.StatusOK, body: `{"error":"Invalid currency pair."}`, message: "Invalid currency pair.", kind: ErrorKindUnknownPair, rejection: true},
+		{name: "JSON error with 4xx", status: http.StatusForbidden, body: `{"error":"Invalid API key/secret pair."}`, message: "Invalid API key/secret pair.", kind: ErrorKindInvalidKey, rejection: true},
+		{name: "JSON error with 5xx", status: http.StatusInternalServerError, body: `{"error":"Internal error."}`, message: "Internal error.", kind: ErrorKindUnknown},
+		{name: "status without message", status: http.StatusBadGateway, body: ``, message: "Bad Gateway", kind: ErrorKindUnknown},
+		{name: "HTML page", status: http.StatusOK, body: `<html></html>`, html: true, message: "unexpected HTML response", kind: ErrorKindUnexpectedResponse},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newFakeServer(t)
+			if tt.html {
+				s.respondHTML("returnCurrencies", tt.status, tt.body)
+			} else {
+				s.respond("returnCurrencies", tt.status, tt.body)
+			}
+
+			_, err := newTestClient(s, WithRetryPolicy(nil)).GetCurrencies(context.Background())
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected an APIError, got %v", err)
+			}
+
+			if apiErr.StatusCode != tt.status || apiErr.Command != "returnCurrencies" || apiErr.Message != tt.message || apiErr.Kind != tt.kind {
+				t.Errorf("unexpected error %+v", apiErr)
+			}
+
+			if isRejection(err) != tt.rejection {
+				t.Errorf("expected isRejection to be %v", tt.rejection)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package poloniex
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// FeeRole tells whether an order takes liquidity from the order book, or makes it by staying in the book.
+type FeeRole int
+
+// Possible FeeRole values.
+const (
+	Taker FeeRole = iota
+	Maker
+)
+
+type FeeInfo struct {
+	MakerFee        Decimal
+	TakerFee        Decimal
+	ThirtyDayVolume Decimal
+
+	// 30-day volume in BTC needed to reach the next fee tier.
+	NextTier Decimal
+}
+
+// TradeEstimate is the outcome of a hypothetical trade, fees included.
+type TradeEstimate struct {
+	// What is given: the base currency for a buy, the quote currency for a sell.
+	Cost Decimal
+
+	// What is received before fees.
+	Gross Decimal
+
+	// Fee taken by Poloniex on what is received.
+	Fee Decimal
+
+	// What is received, fee deducted.
+	Proceeds Decimal
+}
+
+// Fee returns the fee rate applied to the given role, ex: 0.0025 for 0.25%.
+func (f *FeeInfo) Fee(role FeeRole) Decimal {
+	if role == Maker {
+		return f.MakerFee
+	}
+
+	return f.TakerFee
+}
+
+// EstimateBuy computes the cost in base currency and the proceeds in quote currency of buying amount at rate.
+// Results are rounded to CurrencyPrecision.
+func (f *FeeInfo) EstimateBuy(rate, amount Decimal, role FeeRole) *TradeEstimate {
+	return f.estimate(rate.Mul(amount), amount, role)
+}
+
+// EstimateSell computes the cost in quote currency and the proceeds in base currency of selling amount at rate.
+// Results are rounded to CurrencyPrecision.
+func (f *FeeInfo) EstimateSell(rate, amount Decimal, role FeeRole) *TradeEstimate {
+	return f.estimate(amount, rate.Mul(amount), role)
+}
+
+// Poloniex takes its fee on what is received.
+func (f *FeeInfo) estimate(cost, gross Decimal, role FeeRole) *TradeEstimate {
+	cost = cost.Round(CurrencyPrecision)
+	gross = gross.Round(CurrencyPrecision)
+	fee := gross.Mul(f.Fee(role)).Round(CurrencyPrecision)
+
+	return &TradeEstimate{
+		Cost:     cost,
+		Gross:    gross,
+		Fee:      fee,
+		Proceeds: gross.Sub(fee),
+	}
+}
+
+// Values of returnFeeInfo are usually strings, but nextTier is sometimes a number.
+type feeInfoFromJSON struct {
+	MakerFee        json.RawMessage `json:"makerFee"`
+	TakerFee        json.RawMessage `json:"takerFee"`
+	ThirtyDayVolume json.RawMessage `json:"thirtyDayVolume"`
+	NextTier        json.RawMessage `json:"nextTier"`
+}
+
+func (c *client) GetFeeInfo(ctx context.Context) (*FeeInfo, error) {
+	f := &feeInfoFromJSON{}
+
+	if err := c.tradeCall(ctx, "returnFeeInfo", f); err != nil {
+		return nil, err
+	}
+
+	p := c.newRowParser("returnFeeInfo")
+	row := p.row("fees")
+
+	feeInfo := &FeeInfo{
+		MakerFee:        row.jsonDecimal("makerFee", f.MakerFee),
+		TakerFee:        row.jsonDecimal("takerFee", f.TakerFee),
+		ThirtyDayVolume: row.jsonDecimal("thirtyDayVolume", f.ThirtyDayVolume),
+	}
+
+	// There is no next tier for the highest one.
+	if len(f.NextTier) > 0 {
+		feeInfo.NextTier = row.jsonDecimal("nextTier", f.NextTier)
+	}
+
+	// Fees are the whole point of this call, so they can't be skipped even in lenient mode.
+	if row.invalid {
+		return nil, p.errs
+	}
+
+	return feeInfo, nil
+}
@@ -0,0 +1,52 @@
+package poloniex
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestGetFeeInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		nextTier string
+	}{
+		{name: "next tier as a string", body: `{"makerFee":"0.00140000","takerFee":"0.00240000","thirtyDayVolume":"612.00248891","nextTier":"1200.00000000"}`, nextTier: "1200"},
+		{name: "next tier as a number", body: `{"makerFee":"0.00140000","takerFee":"0.00240000","thirtyDayVolume":"612.00248891","nextTier":1200}`, nextTier: "1200"},
+		{name: "highest tier", body: `{"makerFee":"0.00140000","takerFee":"0.00240000","thirtyDayVolume":"612.00248891"}`, nextTier: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newFakeServer(t)
+			s.respond("returnFeeInfo", http.StatusOK, tt.body)
+
+			f, err := newTestClient(s).GetFeeInfo(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !f.MakerFee.Equal(MustParseDecimal("0.0014")) || !f.TakerFee.Equal(MustParseDecimal("0.0024")) ||
+				!f.ThirtyDayVolume.Equal(MustParseDecimal("612.00248891")) || !f.NextTier.Equal(MustParseDecimal(tt.nextTier)) {
+				t.Errorf("unexpected fee info %+v", f)
+			}
+		})
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	f := &FeeInfo{MakerFee: MustParseDecimal("0.0015"), TakerFee: MustParseDecimal("0.0025")}
+
+	buy := f.EstimateBuy(MustParseDecimal("0.02"), MustParseDecimal("10"), Taker)
+	if !buy.Cost.Equal(MustParseDecimal("0.2")) || !buy.Gross.Equal(MustParseDecimal("10")) ||
+		!buy.Fee.Equal(MustParseDecimal("0.025")) || !buy.Proceeds.Equal(MustParseDecimal("9.975")) {
+		t.Errorf("unexpected buy estimate %+v", buy)
+	}
+
+	sell := f.EstimateSell(MustParseDecimal("0.02"), MustParseDecimal("10"), Maker)
+	if !sell.Cost.Equal(MustParseDecimal("10")) || !sell.Gross.Equal(MustParseDecimal("0.2")) ||
+		!sell.Fee.Equal(MustParseDecimal("0.0003")) || !sell.Proceeds.Equal(MustParseDecimal("0.1997")) {
+		t.Errorf("unexpected sell estimate %+v", sell)
+	}
+}
@@ -0,0 +1,437 @@
+package poloniex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// Poloniex accepts loan durations between 2 and 60 days.
+const (
+	MinLoanDuration = 2
+	MaxLoanDuration = 60
+)
+
+// Lending is the interface to lend funds with Poloniex API.
+type Lending interface {
+	// Creates a loan offer for a given currency, amount, duration in days and daily lending rate.
+	// Returns the ID of the new offer.
+	CreateLoanOffer(ctx context.Context, currency string, amount Decimal, duration int, autoRenew bool, lendingRate Decimal) (int64, error)
+
+	// Cancels one of your loan offers.
+	CancelLoanOffer(ctx context.Context, offerID int64) error
+
+	// Returns your open loan offers for each currency.
+	GetOpenLoanOffers(ctx context.Context) ([]*LoanOffer, error)
+
+	// Returns your active loans, those you provided and those you used.
+	GetActiveLoans(ctx context.Context) (*ActiveLoans, error)
+
+	// Toggles the auto-renew setting on an active loan.
+	// Returns whether auto-renew is now enabled.
+	ToggleAutoRenew(ctx context.Context, loanID int64) (bool, error)
+
+	// Returns your closed loans between start and end, most recent first.
+	// start is required. If end is zero, it defaults to now. A limit of 0 lets Poloniex apply its own limit.
+	GetLendingHistory(ctx context.Context, start, end time.Time, limit int) ([]*LendingHistoryEntry, error)
+}
+
+type LoanOffer struct {
+	ID        int64
+	Currency  string
+	Rate      Decimal
+	Amount    Decimal
+	Duration  int
+	AutoRenew bool
+	Date      time.Time
+}
+
+type ActiveLoan struct {
+	ID        int64
+	Currency  string
+	Rate      Decimal
+	Amount    Decimal
+	Duration  int
+	AutoRenew bool
+	Date      time.Time
+	Fees      Decimal
+}
+
+type ActiveLoans struct {
+	// Loans you provided to others.
+	Provided []*ActiveLoan
+
+	// Loans you used for margin trading.
+	Used []*ActiveLoan
+}
+
+// LendingHistoryEntry is a closed loan you provided.
+type LendingHistoryEntry struct {
+	ID       int64
+	Currency string
+	Rate     Decimal
+	Amount   Decimal
+
+	// Duration of the loan in days, ex: 0.4761 for about 11 hours.
+	Duration Decimal
+
+	// Interest paid by the borrower.
+	Interest Decimal
+
+	// Fee taken by Poloniex, negative.
+	Fee Decimal
+
+	// Net interest, Interest + Fee.
+	Earned Decimal
+
+	Open  time.Time
+	Close time.Time
+}
+
+// LendingEarnings sums the loans of a currency closed the same day.
+type LendingEarnings struct {
+	Currency string
+
+	// Day is the UTC midnight of the day the loans were closed.
+	Day time.Time
+
+	Loans    int
+	Interest Decimal
+	Fee      Decimal
+	Earned   Decimal
+}
+
+// AggregateLendingEarnings totals the history per currency and per UTC day of closing.
+// The result is sorted by day, then by currency.
+func AggregateLendingEarnings(history []*LendingHistoryEntry) []*LendingEarnings {
+	type dayKey struct {
+		currency string
+		day      time.Time
+	}
+
+	byDay := make(map[dayKey]*LendingEarnings)
+	for _, h := range history {
+		closed := h.Close.UTC()
+		k := dayKey{currency: h.Currency, day: time.Date(closed.Year(), closed.Month(), closed.Day(), 0, 0, 0, 0, time.UTC)}
+
+		e, ok := byDay[k]
+		if !ok {
+			e = &LendingEarnings{Currency: k.currency, Day: k.day}
+			byDay[k] = e
+		}
+
+		e.Loans++
+		e.Interest = e.Interest.Add(h.Interest)
+		e.Fee = e.Fee.Add(h.Fee)
+		e.Earned = e.Earned.Add(h.Earned)
+	}
+
+	earnings := make([]*LendingEarnings, 0, len(byDay))
+	for _, e := range byDay {
+		earnings = append(earnings, e)
+	}
+
+	sort.Slice(earnings, func(i, j int) bool {
+		if !earnings[i].Day.Equal(earnings[j].Day) {
+			return earnings[i].Day.Before(earnings[j].Day)
+		}
+
+		return earnings[i].Currency < earnings[j].Currency
+	})
+
+	return earnings
+}
+
+// TotalLendingEarnings returns the net interest earned per currency over the whole history.
+func TotalLendingEarnings(history []*LendingHistoryEntry) map[string]Decimal {
+	totals := make(map[string]Decimal)
+	for _, h := range history {
+		totals[h.Currency] = totals[h.Currency].Add(h.Earned)
+	}
+
+	return totals
+}
+
+type createLoanOfferFromJSON struct {
+	Success int    `json:"success"`
+	Message string `json:"message"`
+	OrderID int64  `json:"orderID"`
+}
+
+// The message of toggleAutoRenew is the new auto-renew setting, 0 or 1,
+// but it's a string when something goes wrong.
+type lendingResultFromJSON struct {
+	Success int             `json:"success"`
+	Message json.RawMessage `json:"message"`
+}
+
+// message returns the message as text, without the quotes when it's a JSON string.
+func (r *lendingResultFromJSON) message() string {
+	var m string
+	if err := json.Unmarshal(r.Message, &m); err != nil {
+		return string(r.Message)
+	}
+
+	return m
+}
+
+// Rates and amounts of returnOpenLoanOffers and returnActiveLoans are strings, converted to decimals in LoanOffer and ActiveLoan.
+// Active loans give their duration as "range".
+type loanOfferFromJSON struct {
+	ID        int64  `json:"id"`
+	Currency  string `json:"currency"`
+	Rate      string `json:"rate"`
+	Amount    string `json:"amount"`
+	Duration  int    `json:"duration"`
+	Range     int    `json:"range"`
+	AutoRenew int    `json:"autoRenew"`
+	Date      string `json:"date"`
+	Fees      string `json:"fees"`
+}
+
+type lendingHistoryEntryFromJSON struct {
+	ID       int64  `json:"id"`
+	Currency string `json:"currency"`
+	Rate     string `json:"rate"`
+	Amount   string `json:"amount"`
+	Duration string `json:"duration"`
+	Interest string `json:"interest"`
+	Fee      string `json:"fee"`
+	Earned   string `json:"earned"`
+	Open     string `json:"open"`
+	Close    string `json:"close"`
+}
+
+type activeLoansFromJSON struct {
+	Provided []*loanOfferFromJSON `json:"provided"`
+	Used     []*loanOfferFromJSON `json:"used"`
+}
+
+func (c *client) CreateLoanOffer(ctx context.Context, currency string, amount Decimal, duration int, autoRenew bool, lendingRate Decimal) (int64, error) {
+	if amount.Sign() <= 0 {
+		return 0, fmt.Errorf("poloniex: loan amount must be positive, got %s", amount)
+	}
+
+	if duration < MinLoanDuration || duration > MaxLoanDuration {
+		return 0, fmt.Errorf("poloniex: loan duration must be between %d and %d days, got %d", MinLoanDuration, MaxLoanDuration, duration)
+	}
+
+	if lendingRate.Sign() <= 0 {
+		return 0, fmt.Errorf("poloniex: lending rate must be positive, got %s", lendingRate)
+	}
+
+	autoRenewValue := "0"
+	if autoRenew {
+		autoRenewValue = "1"
+	}
+
+	params := []postParam{
+		postParam{key: "currency", value: currency},
+		postParam{key: "amount", value: amount.String()},
+		postParam{key: "duration", value: fmt.Sprintf("%d", duration)},
+		postParam{key: "autoRenew", value: autoRenewValue},
+		postParam{key: "lendingRate", value: lendingRate.String()},
+	}
+
+	o := &createLoanOfferFromJSON{}
+
+	if err := c.tradeCall(ctx, "createLoanOffer", o, params...); err != nil {
+		return 0, err
+	}
+
+	if o.Success != 1 {
+		return 0, newAPIError(http.StatusOK, "createLoanOffer", o.Message)
+	}
+
+	return o.OrderID, nil
+}
+
+func (c *client) CancelLoanOffer(ctx context.Context, offerID int64) error {
+	r := &lendingResultFromJSON{}
+
+	if err := c.tradeCall(ctx, "cancelLoanOffer", r, postParam{key: "orderNumber", value: fmt.Sprintf("%d", offerID)}); err != nil {
+		return err
+	}
+
+	if r.Success != 1 {
+		return newAPIError(http.StatusOK, "cancelLoanOffer", r.message())
+	}
+
+	return nil
+}
+
+func (c *client) GetOpenLoanOffers(ctx context.Context) ([]*LoanOffer, error) {
+	// Poloniex returns an empty array instead of an empty map when there is no offer.
+	raw := json.RawMessage{}
+
+	if err := c.tradeCall(ctx, "returnOpenLoanOffers", &raw); err != nil {
+		return nil, err
+	}
+
+	if isEmptyJSONArray(raw) {
+		return []*LoanOffer{}, nil
+	}
+
+	offersFromJSON := make(map[string][]*loanOfferFromJSON)
+	if err := json.Unmarshal(raw, &offersFromJSON); err != nil {
+		return nil, fmt.Errorf("poloniex: unable to decode returnOpenLoanOffers response: %v", err)
+	}
+
+	p := c.newRowParser("returnOpenLoanOffers")
+
+	loanOffers := []*LoanOffer{}
+	for currency, offers := range offersFromJSON {
+		for _, o := range offers {
+			row := p.row(fmt.Sprintf("%s offer %d", currency, o.ID))
+
+			loanOffer := &LoanOffer{
+				ID:        o.ID,
+				Currency:  currency,
+				Rate:      row.decimal("rate", o.Rate),
+				Amount:    row.decimal("amount", o.Amount),
+				Duration:  o.Duration,
+				AutoRenew: o.AutoRenew == 1,
+				Date:      row.date("date", o.Date),
+			}
+			if row.invalid {
+				continue
+			}
+
+			loanOffers = append(loanOffers, loanOffer)
+		}
+	}
+
+	if err := p.err(); err != nil {
+		return nil, err
+	}
+
+	return loanOffers, nil
+}
+
+func (c *client) GetActiveLoans(ctx context.Context) (*ActiveLoans, error) {
+	a := &activeLoansFromJSON{}
+
+	if err := c.tradeCall(ctx, "returnActiveLoans", a); err != nil {
+		return nil, err
+	}
+
+	p := c.newRowParser("returnActiveLoans")
+
+	activeLoans := &ActiveLoans{
+		Provided: c.convertActiveLoans(p, "provided", a.Provided),
+		Used:     c.convertActiveLoans(p, "used", a.Used),
+	}
+
+	if err := p.err(); err != nil {
+		return nil, err
+	}
+
+	return activeLoans, nil
+}
+
+func (c *client) convertActiveLoans(p *rowParser, side string, loans []*loanOfferFromJSON) []*ActiveLoan {
+	activeLoans := []*ActiveLoan{}
+	for _, l := range loans {
+		row := p.row(fmt.Sprintf("%s %s loan %d", l.Currency, side, l.ID))
+
+		activeLoan := &ActiveLoan{
+			ID:        l.ID,
+			Currency:  l.Currency,
+			Rate:      row.decimal("rate", l.Rate),
+			Amount:    row.decimal("amount", l.Amount),
+			Duration:  l.Range,
+			AutoRenew: l.AutoRenew == 1,
+			Date:      row.date("date", l.Date),
+			Fees:      row.decimal("fees", l.Fees),
+		}
+		if row.invalid {
+			continue
+		}
+
+		activeLoans = append(activeLoans, activeLoan)
+	}
+
+	return activeLoans
+}
+
+func (c *client) ToggleAutoRenew(ctx context.Context, loanID int64) (bool, error) {
+	r := &lendingResultFromJSON{}
+
+	if err := c.tradeCall(ctx, "toggleAutoRenew", r, postParam{key: "orderNumber", value: fmt.Sprintf("%d", loanID)}); err != nil {
+		return false, err
+	}
+
+	if r.Success != 1 {
+		return false, newAPIError(http.StatusOK, "toggleAutoRenew", r.message())
+	}
+
+	var autoRenew int
+	if err := json.Unmarshal(r.Message, &autoRenew); err != nil {
+		return false, fmt.Errorf("poloniex: unable to decode toggleAutoRenew response: %v", err)
+	}
+
+	return autoRenew == 1, nil
+}
+
+func (c *client) GetLendingHistory(ctx context.Context, start, end time.Time, limit int) ([]*LendingHistoryEntry, error) {
+	if start.IsZero() {
+		return nil, errors.New("poloniex: a start is required to get the lending history")
+	}
+
+	if end.IsZero() {
+		end = c.now()
+	}
+
+	if end.Before(start) {
+		return nil, fmt.Errorf("poloniex: end of lending history range %v is before its start %v", end, start)
+	}
+
+	params := []postParam{
+		postParam{key: "start", value: fmt.Sprintf("%v", start.Unix())},
+		postParam{key: "end", value: fmt.Sprintf("%v", end.Unix())},
+	}
+	if limit > 0 {
+		params = append(params, postParam{key: "limit", value: fmt.Sprintf("%v", limit)})
+	}
+
+	entriesFromJSON := []*lendingHistoryEntryFromJSON{}
+
+	if err := c.tradeCall(ctx, "returnLendingHistory", &entriesFromJSON, params...); err != nil {
+		return nil, err
+	}
+
+	p := c.newRowParser("returnLendingHistory")
+
+	history := []*LendingHistoryEntry{}
+	for _, h := range entriesFromJSON {
+		row := p.row(fmt.Sprintf("%s loan %d", h.Currency, h.ID))
+
+		entry := &LendingHistoryEntry{
+			ID:       h.ID,
+			Currency: h.Currency,
+			Rate:     row.decimal("rate", h.Rate),
+			Amount:   row.decimal("amount", h.Amount),
+			Duration: row.decimal("duration", h.Duration),
+			Interest: row.decimal("interest", h.Interest),
+			Fee:      row.decimal("fee", h.Fee),
+			Earned:   row.decimal("earned", h.Earned),
+			Open:     row.date("open", h.Open),
+			Close:    row.date("close", h.Close),
+		}
+		if row.invalid {
+			continue
+		}
+
+		history = append(history, entry)
+	}
+
+	if err := p.err(); err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
@@ -0,0 +1,262 @@
+package poloniex
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// Sample response of returnLendingHistory from the Poloniex documentation.
+const lendingHistoryResponse = `[
+	{"id":246300115,"currency":"BTC","rate":"0.00013890","amount":"0.33714830","duration":"0.00090000","interest":"0.00000005","fee":"0.00000000","earned":"0.00000005","open":"2017-01-01 23:41:37","close":"2017-01-01 23:42:51"},
+	{"id":246294775,"currency":"BTC","rate":"0.00013890","amount":"0.32457133","duration":"0.00430000","interest":"0.00000024","fee":"-0.00000004","earned":"0.00000020","open":"2017-01-01 23:36:32","close":"2017-01-01 23:42:51"},
+	{"id":246251011,"currency":"ETH","rate":"0.00007000","amount":"45.25900000","duration":"1.00000000","interest":"0.00316813","fee":"-0.00047522","earned":"0.00269291","open":"2016-12-31 22:50:01","close":"2017-01-01 22:50:09"},
+	{"id":246239011,"currency":"BTC","rate":"0.00020000","amount":"1.00000000","duration":"2.00000000","interest":"0.00040000","fee":"-0.00006000","earned":"0.00034000","open":"2016-12-30 10:00:00","close":"2017-01-02 00:00:01"}
+]`
+
+func TestGetLendingHistory(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnLendingHistory", http.StatusOK, lendingHistoryResponse)
+
+	c := newTestClient(s, WithParseMode(StrictParsing))
+
+	start := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	history, err := c.GetLendingHistory(context.Background(), start, time.Time{}, 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(history) != 4 {
+		t.Fatalf("expected 4 entries, got %d", len(history))
+	}
+
+	form := s.lastForm("returnLendingHistory")
+	if form["start"] != "1483228800" || form["end"] != "1525176000" || form["limit"] != "100" {
+		t.Errorf("unexpected parameters %v", form)
+	}
+
+	h := history[1]
+	if h.ID != 246294775 || h.Currency != "BTC" ||
+		!h.Rate.Equal(MustParseDecimal("0.0001389")) ||
+		!h.Amount.Equal(MustParseDecimal("0.32457133")) ||
+		!h.Duration.Equal(MustParseDecimal("0.0043")) ||
+		!h.Interest.Equal(MustParseDecimal("0.00000024")) ||
+		!h.Fee.Equal(MustParseDecimal("-0.00000004")) ||
+		!h.Earned.Equal(MustParseDecimal("0.0000002")) ||
+		!h.Open.Equal(time.Date(2017, 1, 1, 23, 36, 32, 0, time.UTC)) ||
+		!h.Close.Equal(time.Date(2017, 1, 1, 23, 42, 51, 0, time.UTC)) {
+		t.Errorf("unexpected entry %+v", h)
+	}
+}
+
+func TestGetLendingHistoryRequiresStart(t *testing.T) {
+	s := newFakeServer(t)
+
+	c := newTestClient(s)
+
+	if _, err := c.GetLendingHistory(context.Background(), time.Time{}, time.Time{}, 0); err == nil {
+		t.Fatal("expected an error without start")
+	}
+
+	if n := s.callCount("returnLendingHistory"); n != 0 {
+		t.Errorf("expected no call, got %d", n)
+	}
+}
+
+func TestAggregateLendingEarnings(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnLendingHistory", http.StatusOK, lendingHistoryResponse)
+
+	history, err := newTestClient(s).GetLendingHistory(context.Background(), time.Unix(0, 0), time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []struct {
+		currency string
+		day      time.Time
+		loans    int
+		interest string
+		fee      string
+		earned   string
+	}{
+		{currency: "BTC", day: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), loans: 2, interest: "0.00000029", fee: "-0.00000004", earned: "0.00000025"},
+		{currency: "ETH", day: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), loans: 1, interest: "0.00316813", fee: "-0.00047522", earned: "0.00269291"},
+		{currency: "BTC", day: time.Date(2017, 1, 2, 0, 0, 0, 0, time.UTC), loans: 1, interest: "0.0004", fee: "-0.00006", earned: "0.00034"},
+	}
+
+	earnings := AggregateLendingEarnings(history)
+	if len(earnings) != len(expected) {
+		t.Fatalf("expected %d days, got %d", len(expected), len(earnings))
+	}
+
+	for i, e := range expected {
+		got := earnings[i]
+		if got.Currency != e.currency || !got.Day.Equal(e.day) || got.Loans != e.loans ||
+			!got.Interest.Equal(MustParseDecimal(e.interest)) ||
+			!got.Fee.Equal(MustParseDecimal(e.fee)) ||
+			!got.Earned.Equal(MustParseDecimal(e.earned)) {
+			t.Errorf("unexpected earnings at %d: %+v", i, got)
+		}
+	}
+
+	totals := TotalLendingEarnings(history)
+	if len(totals) != 2 || !totals["BTC"].Equal(MustParseDecimal("0.00034025")) || !totals["ETH"].Equal(MustParseDecimal("0.00269291")) {
+		t.Errorf("unexpected totals %v", totals)
+	}
+}
+
+func TestGetOpenLoanOffers(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected int
+		fails    bool
+	}{
+		{
+			name:     "offers",
+			body:     `{"BTC":[{"id":10595,"rate":"0.00020000","amount":"3.00000000","duration":2,"autoRenew":1,"date":"2015-05-10 23:33:50"}],"LTC":[{"id":10598,"rate":"0.00002100","amount":"10.00000000","duration":2,"autoRenew":0,"date":"2015-05-10 23:34:35"}]}`,
+			expected: 2,
+		},
+		{name: "no offer", body: `[]`, expected: 0},
+		{name: "unexpected format", body: `{"BTC":{"id":10595}}`, fails: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newFakeServer(t)
+			s.respond("returnOpenLoanOffers", http.StatusOK, tt.body)
+
+			offers, err := newTestClient(s, WithParseMode(StrictParsing)).GetOpenLoanOffers(context.Background())
+			if tt.fails {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(offers) != tt.expected {
+				t.Fatalf("expected %d offers, got %d", tt.expected, len(offers))
+			}
+
+			for _, o := range offers {
+				if o.ID == 10595 && (o.Currency != "BTC" || !o.Rate.Equal(MustParseDecimal("0.0002")) || !o.Amount.Equal(MustParseDecimal("3")) ||
+					o.Duration != 2 || !o.AutoRenew || !o.Date.Equal(time.Date(2015, 5, 10, 23, 33, 50, 0, time.UTC))) {
+					t.Errorf("unexpected offer %+v", o)
+				}
+			}
+		})
+	}
+}
+
+func TestGetActiveLoans(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnActiveLoans", http.StatusOK, `{"provided":[{"id":75073,"currency":"LTC","rate":"0.00020000","amount":"0.72234880","range":2,"autoRenew":0,"date":"2015-05-10 23:45:05","fees":"0.00006000"}],"used":[{"id":75238,"currency":"BTC","rate":"0.00020000","amount":"0.04843834","range":2,"date":"2015-05-10 23:51:12","fees":"-0.00000001"}]}`)
+
+	loans, err := newTestClient(s, WithParseMode(StrictParsing)).GetActiveLoans(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(loans.Provided) != 1 || len(loans.Used) != 1 {
+		t.Fatalf("unexpected loans %+v", loans)
+	}
+
+	p := loans.Provided[0]
+	if p.ID != 75073 || p.Currency != "LTC" || p.Duration != 2 || p.AutoRenew || !p.Fees.Equal(MustParseDecimal("0.00006")) {
+		t.Errorf("unexpected provided loan %+v", p)
+	}
+
+	if u := loans.Used[0]; u.ID != 75238 || !u.Fees.Equal(MustParseDecimal("-0.00000001")) {
+		t.Errorf("unexpected used loan %+v", u)
+	}
+}
+
+func TestToggleAutoRenew(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("toggleAutoRenew", http.StatusOK, `{"success":1,"message":0}`)
+
+	autoRenew, err := newTestClient(s).ToggleAutoRenew(context.Background(), 242844)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if autoRenew {
+		t.Error("expected auto-renew to be disabled")
+	}
+
+	if form := s.lastForm("toggleAutoRenew"); form["orderNumber"] != "242844" {
+		t.Errorf("unexpected parameters %v", form)
+	}
+}
+
+func TestCreateLoanOffer(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("createLoanOffer", http.StatusOK, `{"success":1,"message":"Loan order placed.","orderID":10590}`)
+	s.respond("createLoanOffer", http.StatusOK, `{"success":0,"message":"Not enough BTC available to offer."}`)
+
+	c := newTestClient(s)
+
+	id, err := c.CreateLoanOffer(context.Background(), "BTC", MustParseDecimal("0.1"), 2, true, MustParseDecimal("0.00015"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if id != 10590 {
+		t.Errorf("expected offer 10590, got %d", id)
+	}
+
+	form := s.lastForm("createLoanOffer")
+	if form["currency"] != "BTC" || form["amount"] != "0.1" || form["duration"] != "2" || form["autoRenew"] != "1" || form["lendingRate"] != "0.00015" {
+		t.Errorf("unexpected parameters %v", form)
+	}
+
+	_, err = c.CreateLoanOffer(context.Background(), "BTC", MustParseDecimal("100"), 2, false, MustParseDecimal("0.00015"))
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Command != "createLoanOffer" || apiErr.Message != "Not enough BTC available to offer." {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestCancelLoanOffer(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("cancelLoanOffer", http.StatusOK, `{"success":1,"message":"Loan offer canceled."}`)
+	s.respond("cancelLoanOffer", http.StatusOK, `{"success":0,"message":"Error canceling loan order, or you are not the person who placed it."}`)
+
+	c := newTestClient(s)
+
+	if err := c.CancelLoanOffer(context.Background(), 10590); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if form := s.lastForm("cancelLoanOffer"); form["orderNumber"] != "10590" {
+		t.Errorf("unexpected parameters %v", form)
+	}
+
+	err := c.CancelLoanOffer(context.Background(), 10590)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Command != "cancelLoanOffer" || apiErr.Message != "Error canceling loan order, or you are not the person who placed it." {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestToggleAutoRenewFailure(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("toggleAutoRenew", http.StatusOK, `{"success":0,"message":"Invalid loan ID."}`)
+
+	_, err := newTestClient(s).ToggleAutoRenew(context.Background(), 242844)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "Invalid loan ID." {
+		t.Errorf("unexpected error %v", err)
+	}
+}
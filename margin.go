@@ -0,0 +1,324 @@
+package poloniex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Margin is the interface to trade on margin with Poloniex API.
+type Margin interface {
+	// Places a margin buy order in a given market, at the given rate for the given amount.
+	// lendingRate is the maximum lending rate you are willing to accept, zero to use the Poloniex default of 2%.
+	// As for Buy, the call doesn't fail once the order is placed because of a value which can't be parsed.
+	MarginBuy(ctx context.Context, currencyPair CurrencyPair, rate, amount, lendingRate Decimal) (*OrderResult, error)
+
+	// Places a margin sell order in a given market. Parameters and output are the same as for MarginBuy.
+	MarginSell(ctx context.Context, currencyPair CurrencyPair, rate, amount, lendingRate Decimal) (*OrderResult, error)
+
+	// Returns information about your margin position in a given market.
+	// If you have no margin position, the returned position is of type NoPosition.
+	GetMarginPosition(ctx context.Context, currencyPair CurrencyPair) (*MarginPosition, error)
+
+	// Returns information about your margin positions in all markets.
+	GetAllMarginPositions(ctx context.Context) ([]*MarginPosition, error)
+
+	// Closes your margin position in a given market using a market order.
+	CloseMarginPosition(ctx context.Context, currencyPair CurrencyPair) (*ClosePositionResult, error)
+
+	// Returns a summary of your entire margin account.
+	GetMarginAccountSummary(ctx context.Context) (*MarginAccountSummary, error)
+
+	// Returns your current tradable balances for each market in which margin trading is enabled,
+	// meaning the amounts of base and quote currencies available to margin orders.
+	GetTradableBalances(ctx context.Context) ([]*TradableBalance, error)
+}
+
+// PositionType is the type of a margin position.
+type PositionType string
+
+// Possible PositionType values.
+const (
+	LongPosition  PositionType = "long"
+	ShortPosition PositionType = "short"
+	NoPosition    PositionType = "none"
+)
+
+type MarginPosition struct {
+	Pair   CurrencyPair
+	Type   PositionType
+	Amount Decimal
+	Total  Decimal
+
+	BasePrice Decimal
+
+	// Estimated price at which the position would be liquidated, -1 when there is no position.
+	LiquidationPrice Decimal
+
+	ProfitLoss  Decimal
+	LendingFees Decimal
+}
+
+type ClosePositionResult struct {
+	// Message returned by Poloniex, ex: "Successfully closed margin position."
+	Message string
+
+	ResultingTrades []*ResultingTrade
+}
+
+type MarginAccountSummary struct {
+	TotalValue         Decimal
+	ProfitLoss         Decimal
+	LendingFees        Decimal
+	NetValue           Decimal
+	TotalBorrowedValue Decimal
+	CurrentMargin      Decimal
+}
+
+type TradableBalance struct {
+	Pair CurrencyPair
+
+	// Tradable amounts of the base and quote currencies of Pair.
+	Base  Decimal
+	Quote Decimal
+}
+
+// Values of getMarginPosition are usually strings, but liquidationPrice is a number when there is no position.
+type marginPositionFromJSON struct {
+	Amount           json.RawMessage `json:"amount"`
+	Total            json.RawMessage `json:"total"`
+	BasePrice        json.RawMessage `json:"basePrice"`
+	LiquidationPrice json.RawMessage `json:"liquidationPrice"`
+	PL               json.RawMessage `json:"pl"`
+	LendingFees      json.RawMessage `json:"lendingFees"`
+	Type             string          `json:"type"`
+}
+
+// Values of returnMarginAccountSummary are strings, converted to decimals in MarginAccountSummary.
+type marginAccountSummaryFromJSON struct {
+	TotalValue         string `json:"totalValue"`
+	PL                 string `json:"pl"`
+	LendingFees        string `json:"lendingFees"`
+	NetValue           string `json:"netValue"`
+	TotalBorrowedValue string `json:"totalBorrowedValue"`
+	CurrentMargin      string `json:"currentMargin"`
+}
+
+func (c *client) MarginBuy(ctx context.Context, currencyPair CurrencyPair, rate, amount, lendingRate Decimal) (*OrderResult, error) {
+	return c.placeMarginOrder(ctx, "marginBuy", currencyPair, rate, amount, lendingRate)
+}
+
+func (c *client) MarginSell(ctx context.Context, currencyPair CurrencyPair, rate, amount, lendingRate Decimal) (*OrderResult, error) {
+	return c.placeMarginOrder(ctx, "marginSell", currencyPair, rate, amount, lendingRate)
+}
+
+func (c *client) placeMarginOrder(ctx context.Context, command string, currencyPair CurrencyPair, rate, amount, lendingRate Decimal) (*OrderResult, error) {
+	if err := currencyPair.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := c.validateOrder(currencyPair, rate, amount); err != nil {
+		return nil, err
+	}
+
+	if lendingRate.Sign() < 0 {
+		return nil, fmt.Errorf("poloniex: lending rate can't be negative, got %s", lendingRate)
+	}
+
+	params := []postParam{
+		postParam{key: "currencyPair", value: currencyPair.String()},
+		postParam{key: "rate", value: rate.String()},
+		postParam{key: "amount", value: amount.String()},
+	}
+	if !lendingRate.IsZero() {
+		params = append(params, postParam{key: "lendingRate", value: lendingRate.String()})
+	}
+
+	o := &orderResultByPairFromJSON{}
+
+	if err := c.tradeCall(ctx, command, o, params...); err != nil {
+		return nil, err
+	}
+
+	if o.Success != 1 {
+		return nil, newAPIError(http.StatusOK, command, o.Message)
+	}
+
+	return c.convertOrderResult(command, o.flatten())
+}
+
+func (c *client) GetMarginPosition(ctx context.Context, currencyPair CurrencyPair) (*MarginPosition, error) {
+	if currencyPair == AllPairs {
+		return nil, errors.New("use GetAllMarginPositions to get margin positions of all markets")
+	}
+
+	if err := currencyPair.Validate(); err != nil {
+		return nil, err
+	}
+
+	m := &marginPositionFromJSON{}
+
+	if err := c.tradeCall(ctx, "getMarginPosition", m, postParam{key: "currencyPair", value: currencyPair.String()}); err != nil {
+		return nil, err
+	}
+
+	p := c.newRowParser("getMarginPosition")
+
+	marginPosition, ok := c.convertMarginPosition(p.row(currencyPair.String()), currencyPair, m)
+	if !ok {
+		// A single position can't be skipped.
+		return nil, p.errs
+	}
+
+	return marginPosition, nil
+}
+
+func (c *client) GetAllMarginPositions(ctx context.Context) ([]*MarginPosition, error) {
+	// Poloniex returns an empty array instead of an empty map when there is no market.
+	raw := json.RawMessage{}
+
+	if err := c.tradeCall(ctx, "getMarginPosition", &raw, postParam{key: "currencyPair", value: AllPairs.String()}); err != nil {
+		return nil, err
+	}
+
+	marginPositions := []*MarginPosition{}
+	if isEmptyJSONArray(raw) {
+		return marginPositions, nil
+	}
+
+	m := make(map[CurrencyPair]*marginPositionFromJSON)
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, fmt.Errorf("poloniex: unable to decode getMarginPosition response: %v", err)
+	}
+
+	p := c.newRowParser("getMarginPosition")
+
+	for k, v := range m {
+		marginPosition, ok := c.convertMarginPosition(p.row(k.String()), k, v)
+		if !ok {
+			continue
+		}
+
+		marginPositions = append(marginPositions, marginPosition)
+	}
+
+	if err := p.err(); err != nil {
+		return nil, err
+	}
+
+	return marginPositions, nil
+}
+
+func (c *client) convertMarginPosition(row *parsedRow, currencyPair CurrencyPair, m *marginPositionFromJSON) (*MarginPosition, bool) {
+	marginPosition := &MarginPosition{
+		Pair:             currencyPair,
+		Type:             PositionType(m.Type),
+		Amount:           row.jsonDecimal("amount", m.Amount),
+		Total:            row.jsonDecimal("total", m.Total),
+		BasePrice:        row.jsonDecimal("basePrice", m.BasePrice),
+		LiquidationPrice: row.jsonDecimal("liquidationPrice", m.LiquidationPrice),
+		ProfitLoss:       row.jsonDecimal("pl", m.PL),
+		LendingFees:      row.jsonDecimal("lendingFees", m.LendingFees),
+	}
+
+	return marginPosition, !row.invalid
+}
+
+func (c *client) CloseMarginPosition(ctx context.Context, currencyPair CurrencyPair) (*ClosePositionResult, error) {
+	if err := currencyPair.Validate(); err != nil {
+		return nil, err
+	}
+
+	o := &orderResultByPairFromJSON{}
+
+	if err := c.tradeCall(ctx, "closeMarginPosition", o, postParam{key: "currencyPair", value: currencyPair.String()}); err != nil {
+		return nil, err
+	}
+
+	if o.Success != 1 {
+		return nil, newAPIError(http.StatusOK, "closeMarginPosition", o.Message)
+	}
+
+	p := c.newRowParser("closeMarginPosition")
+
+	closePositionResult := &ClosePositionResult{
+		Message:         o.Message,
+		ResultingTrades: c.convertResultingTrades(p, o.flatten().ResultingTrades),
+	}
+
+	// The position was closed anyway, so parse errors are only reported.
+	p.report()
+
+	return closePositionResult, nil
+}
+
+func (c *client) GetMarginAccountSummary(ctx context.Context) (*MarginAccountSummary, error) {
+	m := &marginAccountSummaryFromJSON{}
+
+	if err := c.tradeCall(ctx, "returnMarginAccountSummary", m); err != nil {
+		return nil, err
+	}
+
+	p := c.newRowParser("returnMarginAccountSummary")
+	row := p.row("summary")
+
+	marginAccountSummary := &MarginAccountSummary{
+		TotalValue:         row.decimal("totalValue", m.TotalValue),
+		ProfitLoss:         row.decimal("pl", m.PL),
+		LendingFees:        row.decimal("lendingFees", m.LendingFees),
+		NetValue:           row.decimal("netValue", m.NetValue),
+		TotalBorrowedValue: row.decimal("totalBorrowedValue", m.TotalBorrowedValue),
+		CurrentMargin:      row.decimal("currentMargin", m.CurrentMargin),
+	}
+
+	// A single summary can't be skipped.
+	if row.invalid {
+		return nil, p.errs
+	}
+
+	return marginAccountSummary, nil
+}
+
+func (c *client) GetTradableBalances(ctx context.Context) ([]*TradableBalance, error) {
+	// Poloniex returns an empty array instead of an empty map when margin trading isn't available.
+	raw := json.RawMessage{}
+
+	if err := c.tradeCall(ctx, "returnTradableBalances", &raw); err != nil {
+		return nil, err
+	}
+
+	tradableBalances := []*TradableBalance{}
+	if isEmptyJSONArray(raw) {
+		return tradableBalances, nil
+	}
+
+	balancesFromJSON := make(map[CurrencyPair]map[string]string)
+	if err := json.Unmarshal(raw, &balancesFromJSON); err != nil {
+		return nil, fmt.Errorf("poloniex: unable to decode returnTradableBalances response: %v", err)
+	}
+
+	p := c.newRowParser("returnTradableBalances")
+
+	for k, v := range balancesFromJSON {
+		row := p.row(k.String())
+
+		tradableBalance := &TradableBalance{
+			Pair:  k,
+			Base:  row.decimal(k.Base(), v[k.Base()]),
+			Quote: row.decimal(k.Quote(), v[k.Quote()]),
+		}
+		if row.invalid {
+			continue
+		}
+
+		tradableBalances = append(tradableBalances, tradableBalance)
+	}
+
+	if err := p.err(); err != nil {
+		return nil, err
+	}
+
+	return tradableBalances, nil
+}
@@ -0,0 +1,183 @@
+package poloniex
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestMarginBuy(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("marginBuy", http.StatusOK, `{"success":1,"message":"Margin order placed.","orderNumber":"154407998","resultingTrades":{"BTC_DASH":[{"amount":"1.00000000","date":"2015-05-10 22:47:05","rate":"0.01383692","total":"0.01383692","tradeID":"1213556","type":"buy"}]}}`)
+
+	r, err := newTestClient(s, WithParseMode(StrictParsing)).MarginBuy(context.Background(), "BTC_DASH", MustParseDecimal("0.01383692"), MustParseDecimal("1"), MustParseDecimal("0.0002"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if form := s.lastForm("marginBuy"); form["currencyPair"] != "BTC_DASH" || form["lendingRate"] != "0.0002" {
+		t.Errorf("unexpected parameters %v", form)
+	}
+
+	if r.OrderNumber != 154407998 || len(r.ResultingTrades) != 1 || r.ResultingTrades[0].TradeID != 1213556 {
+		t.Errorf("unexpected result %+v", r)
+	}
+}
+
+func TestGetMarginPosition(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected *MarginPosition
+	}{
+		{
+			name: "long position",
+			body: `{"amount":"40.94717831","total":"-0.09671314","basePrice":"0.00236190","liquidationPrice":"-1","pl":"-0.00058655","lendingFees":"-0.00000038","type":"long"}`,
+			expected: &MarginPosition{
+				Type:             LongPosition,
+				Amount:           MustParseDecimal("40.94717831"),
+				Total:            MustParseDecimal("-0.09671314"),
+				BasePrice:        MustParseDecimal("0.0023619"),
+				LiquidationPrice: MustParseDecimal("-1"),
+				ProfitLoss:       MustParseDecimal("-0.00058655"),
+				LendingFees:      MustParseDecimal("-0.00000038"),
+			},
+		},
+		{
+			name: "no position",
+			body: `{"type":"none","basePrice":"0.00000000","amount":"0.00000000","total":"0.00000000","liquidationPrice":-1,"pl":"0.00000000","lendingFees":"0.00000000"}`,
+			expected: &MarginPosition{
+				Type:             NoPosition,
+				LiquidationPrice: MustParseDecimal("-1"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newFakeServer(t)
+			s.respond("getMarginPosition", http.StatusOK, tt.body)
+
+			p, err := newTestClient(s).GetMarginPosition(context.Background(), "BTC_XMR")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			e := tt.expected
+			if p.Pair != "BTC_XMR" || p.Type != e.Type || !p.Amount.Equal(e.Amount) || !p.Total.Equal(e.Total) ||
+				!p.BasePrice.Equal(e.BasePrice) || !p.LiquidationPrice.Equal(e.LiquidationPrice) ||
+				!p.ProfitLoss.Equal(e.ProfitLoss) || !p.LendingFees.Equal(e.LendingFees) {
+				t.Errorf("unexpected position %+v", p)
+			}
+		})
+	}
+}
+
+func TestGetMarginPositionInvalid(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("getMarginPosition", http.StatusOK, `{"type":"long","basePrice":"abc","amount":"1","total":"1","liquidationPrice":"1","pl":"0","lendingFees":"0"}`)
+
+	// Even in lenient mode, a single position can't be skipped.
+	_, err := newTestClient(s).GetMarginPosition(context.Background(), "BTC_XMR")
+
+	var parseErrs ParseErrors
+	if !errors.As(err, &parseErrs) || len(parseErrs) != 1 || parseErrs[0].Field != "basePrice" {
+		t.Fatalf("expected a parse error on basePrice, got %v", err)
+	}
+}
+
+func TestGetAllMarginPositions(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("getMarginPosition", http.StatusOK, `{"BTC_DASH":{"type":"none","basePrice":"0.00000000","amount":"0.00000000","total":"0.00000000","liquidationPrice":-1,"pl":"0.00000000","lendingFees":"0.00000000"},"BTC_XMR":{"type":"short","basePrice":"0.003","amount":"-10","total":"0.03","liquidationPrice":"0.0045","pl":"0.0001","lendingFees":"0"}}`)
+
+	positions, err := newTestClient(s, WithParseMode(StrictParsing)).GetAllMarginPositions(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(positions) != 2 {
+		t.Fatalf("expected 2 positions, got %d", len(positions))
+	}
+
+	if form := s.lastForm("getMarginPosition"); form["currencyPair"] != "all" {
+		t.Errorf("unexpected parameters %v", form)
+	}
+}
+
+func TestGetAllMarginPositionsEmpty(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("getMarginPosition", http.StatusOK, `[]`)
+
+	positions, err := newTestClient(s, WithParseMode(StrictParsing)).GetAllMarginPositions(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if positions == nil || len(positions) != 0 {
+		t.Errorf("expected no position, got %v", positions)
+	}
+}
+
+func TestCloseMarginPosition(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("closeMarginPosition", http.StatusOK, `{"success":1,"message":"Successfully closed margin position.","resultingTrades":{"BTC_XMR":[{"amount":"7.09215901","date":"2015-05-10 22:38:49","rate":"0.00235337","total":"0.01669047","tradeID":"1213346","type":"sell"},{"amount":"24.00289920","date":"2015-05-10 22:38:49","rate":"0.00235321","total":"0.05648386","tradeID":"1213347","type":"sell"}]}}`)
+
+	r, err := newTestClient(s, WithParseMode(StrictParsing)).CloseMarginPosition(context.Background(), "BTC_XMR")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if r.Message != "Successfully closed margin position." || len(r.ResultingTrades) != 2 {
+		t.Errorf("unexpected result %+v", r)
+	}
+}
+
+func TestGetMarginAccountSummary(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnMarginAccountSummary", http.StatusOK, `{"totalValue":"0.00346561","pl":"-0.00001220","lendingFees":"0.00000000","netValue":"0.00345341","totalBorrowedValue":"0.00123220","currentMargin":"2.80263755"}`)
+
+	summary, err := newTestClient(s).GetMarginAccountSummary(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !summary.TotalValue.Equal(MustParseDecimal("0.00346561")) || !summary.ProfitLoss.Equal(MustParseDecimal("-0.0000122")) ||
+		!summary.NetValue.Equal(MustParseDecimal("0.00345341")) || !summary.CurrentMargin.Equal(MustParseDecimal("2.80263755")) {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+}
+
+func TestGetTradableBalances(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnTradableBalances", http.StatusOK, `{"BTC_DASH":{"BTC":"8.50274777","DASH":"654.05752077"},"BTC_LTC":{"BTC":"8.50274777","LTC":"1214.67825290"}}`)
+
+	balances, err := newTestClient(s, WithParseMode(StrictParsing)).GetTradableBalances(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(balances) != 2 {
+		t.Fatalf("expected 2 balances, got %d", len(balances))
+	}
+
+	for _, b := range balances {
+		if b.Pair == "BTC_DASH" && (!b.Base.Equal(MustParseDecimal("8.50274777")) || !b.Quote.Equal(MustParseDecimal("654.05752077"))) {
+			t.Errorf("unexpected balance %+v", b)
+		}
+	}
+}
+
+func TestGetTradableBalancesEmpty(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnTradableBalances", http.StatusOK, `[]`)
+
+	balances, err := newTestClient(s, WithParseMode(StrictParsing)).GetTradableBalances(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if balances == nil || len(balances) != 0 {
+		t.Errorf("expected no balance, got %v", balances)
+	}
+}
@@ -0,0 +1,182 @@
+package poloniex
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NonceManager is a NonceSource generating strictly increasing nonces,
+// even when used by many goroutines at once.
+// Nonces are based on the clock, but never go backwards if the clock does.
+// The zero value is ready to use, and keeps its state in memory only.
+type NonceManager struct {
+	mu   sync.Mutex
+	last int64
+	now  func() time.Time
+
+	// File where the last nonce is persisted, if any.
+	path string
+}
+
+// NewNonceManager returns a NonceManager keeping its state in memory only.
+func NewNonceManager() *NonceManager {
+	return &NonceManager{now: time.Now}
+}
+
+// NewPersistentNonceManager returns a NonceManager persisting every nonce to the file at path,
+// so that a restarted process never reuses a lower nonce.
+// The file is read again before generating each nonce, so processes sharing an API key can share it too.
+// It narrows the window for collisions between processes but doesn't close it,
+// the client recovers from the remaining ones by resyncing and retrying.
+func NewPersistentNonceManager(path string) (*NonceManager, error) {
+	m := &NonceManager{now: time.Now, path: path}
+
+	last, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	m.last = last
+
+	return m, nil
+}
+
+// Nonce returns a nonce greater than any nonce previously returned or persisted.
+func (m *NonceManager) Nonce() (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.path != "" {
+		persisted, err := m.load()
+		if err != nil {
+			return 0, err
+		}
+
+		if persisted > m.last {
+			m.last = persisted
+		}
+	}
+
+	// The zero value of NonceManager uses the system clock.
+	now := m.now
+	if now == nil {
+		now = time.Now
+	}
+
+	nonce := now().UnixNano()
+	if nonce <= m.last {
+		nonce = m.last + 1
+	}
+
+	if err := m.save(nonce); err != nil {
+		return 0, err
+	}
+	m.last = nonce
+
+	return nonce, nil
+}
+
+// Resync makes sure the next nonce is greater than min.
+// It's used to recover when Poloniex reports that a nonce is too low.
+func (m *NonceManager) Resync(min int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if min <= m.last {
+		return nil
+	}
+
+	if err := m.save(min); err != nil {
+		return err
+	}
+	m.last = min
+
+	return nil
+}
+
+func (m *NonceManager) load() (int64, error) {
+	b, err := os.ReadFile(m.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	s := strings.TrimSpace(string(b))
+	if s == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseInt(s, 10, 64)
+}
+
+// save writes the nonce to a temporary file renamed afterwards,
+// so that a crash never leaves a truncated file behind.
+func (m *NonceManager) save(nonce int64) error {
+	if m.path == "" {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(m.path), filepath.Base(m.path)+".tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.WriteString(strconv.FormatInt(nonce, 10)); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+
+		return err
+	}
+
+	return os.Rename(tmp.Name(), m.path)
+}
+
+// Ex: "Nonce must be greater than 1525012345678. You provided 1525012345000."
+var nonceFloorRegexp = regexp.MustCompile(`(?i)nonce must be greater than (\d+)`)
+
+// resyncNonce resyncs the nonce source after Poloniex reported a nonce too low.
+// It returns true if the call can be retried with a new nonce.
+func (c *client) resyncNonce(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Kind != ErrorKindNonceTooLow {
+		return false
+	}
+
+	resyncer, ok := c.nonce.(interface{ Resync(int64) error })
+	if !ok {
+		return false
+	}
+
+	m := nonceFloorRegexp.FindStringSubmatch(apiErr.Message)
+	if m == nil {
+		return false
+	}
+
+	floor, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return false
+	}
+
+	if err := resyncer.Resync(floor); err != nil {
+		c.logger.Errorf("unable to resync nonce: %v", err)
+
+		return false
+	}
+
+	c.logger.Warnf("nonce was too low, resynced to %d", floor)
+
+	return true
+}
@@ -0,0 +1,236 @@
+package poloniex
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNonceManagerIsMonotonic(t *testing.T) {
+	now := time.Unix(1525176000, 0)
+	m := &NonceManager{now: func() time.Time { return now }}
+
+	// The clock is frozen, then goes backwards.
+	last := int64(0)
+	for i := 0; i < 3; i++ {
+		nonce, err := m.Nonce()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if nonce <= last {
+			t.Fatalf("nonce %d is not greater than %d", nonce, last)
+		}
+		last = nonce
+	}
+
+	now = now.Add(-time.Hour)
+
+	nonce, err := m.Nonce()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if nonce <= last {
+		t.Fatalf("nonce %d went backwards from %d with the clock", nonce, last)
+	}
+}
+
+func TestNonceManagerIsMonotonicConcurrently(t *testing.T) {
+	m := NewNonceManager()
+
+	const goroutines, perGoroutine = 8, 200
+
+	var wg sync.WaitGroup
+	nonces := make(chan int64, goroutines*perGoroutine)
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < perGoroutine; j++ {
+				nonce, err := m.Nonce()
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+
+					return
+				}
+				nonces <- nonce
+			}
+		}()
+	}
+	wg.Wait()
+	close(nonces)
+
+	seen := make(map[int64]bool)
+	for nonce := range nonces {
+		if seen[nonce] {
+			t.Fatalf("nonce %d generated twice", nonce)
+		}
+		seen[nonce] = true
+	}
+}
+
+func TestNonceManagerResync(t *testing.T) {
+	m := &NonceManager{now: func() time.Time { return time.Unix(0, 1000) }}
+
+	if err := m.Resync(5000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if nonce, _ := m.Nonce(); nonce != 5001 {
+		t.Errorf("expected 5001 after resync, got %d", nonce)
+	}
+
+	// Resyncing to a lower value never moves the nonce back.
+	if err := m.Resync(10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if nonce, _ := m.Nonce(); nonce != 5002 {
+		t.Errorf("expected 5002, got %d", nonce)
+	}
+}
+
+func TestNonceManagerZeroValue(t *testing.T) {
+	m := &NonceManager{}
+
+	first, err := m.Nonce()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if first < time.Now().Add(-time.Minute).UnixNano() {
+		t.Errorf("expected a nonce based on the system clock, got %d", first)
+	}
+
+	second, err := m.Nonce()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if second <= first {
+		t.Errorf("nonce %d is not greater than %d", second, first)
+	}
+}
+
+func TestZeroNonceManagerAsSource(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnBalances", http.StatusOK, `{}`)
+
+	if _, err := newTestClient(s, WithNonceSource(&NonceManager{})).GetBalances(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if s.lastForm("returnBalances")["nonce"] == "" {
+		t.Error("expected a nonce to be sent")
+	}
+}
+
+func TestPersistentNonceManager(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nonce")
+	clock := func() time.Time { return time.Unix(0, 1000) }
+
+	m, err := NewPersistentNonceManager(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m.now = clock
+
+	if err := m.Resync(9000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	first, err := m.Nonce()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil || strings.TrimSpace(string(b)) != strconv.FormatInt(first, 10) {
+		t.Fatalf("expected %d to be persisted, got %q, %v", first, b, err)
+	}
+
+	// A restarted process, or another one sharing the file, continues from the persisted nonce.
+	restarted, err := NewPersistentNonceManager(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	restarted.now = clock
+
+	second, err := restarted.Nonce()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if second <= first {
+		t.Errorf("nonce %d of the restarted manager is not greater than %d", second, first)
+	}
+
+	third, err := m.Nonce()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if third <= second {
+		t.Errorf("nonce %d is not greater than %d persisted by the other manager", third, second)
+	}
+}
+
+func TestPersistentNonceManagerInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nonce")
+	if err := os.WriteFile(path, []byte("not a nonce"), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := NewPersistentNonceManager(path); err == nil {
+		t.Fatal("expected an error for an invalid file")
+	}
+}
+
+func TestTradeCallResyncsNonce(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnBalances", http.StatusForbidden, `{"error":"Nonce must be greater than 1525176000000000500. You provided 1525176000000000000."}`)
+	s.respond("returnBalances", http.StatusOK, `{"BTC":"1"}`)
+
+	c := newTestClient(s)
+
+	if _, err := c.GetBalances(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if n := s.callCount("returnBalances"); n != 2 {
+		t.Fatalf("expected the call to be sent again after resync, sent %d times", n)
+	}
+
+	if form := s.lastForm("returnBalances"); form["nonce"] != "1525176000000000501" {
+		t.Errorf("expected nonce 1525176000000000501, got %s", form["nonce"])
+	}
+}
+
+func TestTradeCallIsSigned(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnBalances", http.StatusOK, `{}`)
+
+	if _, err := newTestClient(s).GetBalances(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	r := s.lastRequest()
+
+	mac := hmac.New(sha512.New, []byte("secret"))
+	mac.Write([]byte(r.body))
+
+	if r.header.Get("Key") != "key" || r.header.Get("Sign") != hex.EncodeToString(mac.Sum(nil)) {
+		t.Errorf("unexpected headers %v for body %q", r.header, r.body)
+	}
+}
@@ -0,0 +1,119 @@
+package poloniex
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Logger is the logging interface used by the client.
+// The logrus standard logger is used by default, but any structured logger can be plugged in.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// NonceSource generates the nonce sent along every trading call.
+// Poloniex requires each nonce to be greater than the previous one used with the same API key.
+type NonceSource interface {
+	Nonce() (int64, error)
+}
+
+// NonceFunc is an adapter to use an ordinary function as a NonceSource.
+type NonceFunc func() (int64, error)
+
+// Nonce calls f().
+func (f NonceFunc) Nonce() (int64, error) {
+	return f()
+}
+
+// Option configures the client returned by New.
+type Option func(*client)
+
+// WithBaseURL makes the client talk to another Poloniex compatible server,
+// a local fake server in tests for example.
+// Public and trading endpoints are derived from it the same way as from URL.
+func WithBaseURL(baseURL string) Option {
+	return func(c *client) {
+		baseURL = strings.TrimSuffix(baseURL, "/")
+
+		c.publicURL = baseURL + "/public"
+		c.tradeURL = baseURL + "/tradingApi"
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to send requests.
+// The client is used as is, so it should have a timeout.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTransport sets the transport of the HTTP client, to route requests through a proxy for example.
+// The HTTP client set with WithHTTPClient is copied, never modified.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *client) {
+		httpClient := *c.httpClient
+		httpClient.Transport = transport
+
+		c.httpClient = &httpClient
+	}
+}
+
+// WithLogger sets the logger used by the client.
+func WithLogger(logger Logger) Option {
+	return func(c *client) {
+		c.logger = logger
+	}
+}
+
+// WithNonceSource sets the source of nonces sent along trading calls.
+// By default, an in memory NonceManager is used.
+// Sources implementing Resync(int64) error, like NonceManager,
+// are resynced when Poloniex reports a nonce too low, and the call is retried.
+func WithNonceSource(nonce NonceSource) Option {
+	return func(c *client) {
+		c.nonce = nonce
+	}
+}
+
+// WithClock sets the function used by the client to get the current time.
+func WithClock(now func() time.Time) Option {
+	return func(c *client) {
+		c.now = now
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *client) {
+		c.userAgent = userAgent
+	}
+}
+
+func defaultClient(key, secret string) *client {
+	// Poloniex counts public and trading calls together, per IP.
+	limiter := NewRateLimiter(DefaultRequestsPerSecond, 1, false)
+
+	return &client{
+		key:        key,
+		secret:     secret,
+		publicURL:  PublicAPI,
+		tradeURL:   TradeAPI,
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		logger:     logrus.StandardLogger(),
+		now:        time.Now,
+
+		publicLimiter: limiter,
+		tradeLimiter:  limiter,
+
+		publicRetry: &DefaultRetryPolicy,
+
+		minOrderTotals: DefaultMinOrderTotals,
+	}
+}
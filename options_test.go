@@ -0,0 +1,118 @@
+package poloniex
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestWithBaseURL(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnCurrencies", http.StatusOK, `{}`)
+	s.respond("returnBalances", http.StatusOK, `{}`)
+
+	// A trailing slash is ignored.
+	c := newTestClient(s, WithBaseURL(s.URL+"/"))
+
+	if _, err := c.GetCurrencies(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if r := s.lastRequest(); r.method != http.MethodGet || r.path != "/public" {
+		t.Errorf("expected GET /public, got %s %s", r.method, r.path)
+	}
+
+	if _, err := c.GetBalances(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if r := s.lastRequest(); r.method != http.MethodPost || r.path != "/tradingApi" {
+		t.Errorf("expected POST /tradingApi, got %s %s", r.method, r.path)
+	}
+}
+
+func TestWithUserAgent(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnCurrencies", http.StatusOK, `{}`)
+
+	if _, err := newTestClient(s, WithUserAgent("my-bot/1.0")).GetCurrencies(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if ua := s.lastRequest().header.Get("User-Agent"); ua != "my-bot/1.0" {
+		t.Errorf("expected User-Agent my-bot/1.0, got %q", ua)
+	}
+}
+
+// roundTripperFunc is an adapter to use an ordinary function as an http.RoundTripper.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestWithTransport(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnCurrencies", http.StatusOK, `{}`)
+
+	httpClient := &http.Client{Timeout: time.Second}
+
+	used := false
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		used = true
+
+		return http.DefaultTransport.RoundTrip(r)
+	})
+
+	c := newTestClient(s, WithHTTPClient(httpClient), WithTransport(transport))
+
+	if _, err := c.GetCurrencies(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !used {
+		t.Error("expected the transport to be used")
+	}
+
+	if httpClient.Transport != nil {
+		t.Error("expected the provided HTTP client to be left untouched")
+	}
+}
+
+func TestWithClock(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnBalances", http.StatusOK, `{}`)
+
+	if _, err := newTestClient(s).GetBalances(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The default nonce manager is based on the clock of the client.
+	if nonce := s.lastForm("returnBalances")["nonce"]; nonce != "1525176000000000000" {
+		t.Errorf("expected a nonce based on the clock, got %s", nonce)
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	logger := &recordingLogger{}
+
+	New("", "", WithLogger(logger))
+
+	if len(logger.warnings) != 1 {
+		t.Errorf("expected a warning about missing credentials, got %v", logger.warnings)
+	}
+}
+
+// recordingLogger keeps the warnings logged by the client.
+type recordingLogger struct {
+	warnings []string
+}
+
+func (l *recordingLogger) Debugf(format string, args ...interface{}) {}
+func (l *recordingLogger) Infof(format string, args ...interface{})  {}
+func (l *recordingLogger) Errorf(format string, args ...interface{}) {}
+
+func (l *recordingLogger) Warnf(format string, args ...interface{}) {
+	l.warnings = append(l.warnings, format)
+}
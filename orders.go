@@ -0,0 +1,507 @@
+package poloniex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// OrderOption is the execution option of an order.
+type OrderOption string
+
+// Possible OrderOption values.
+const (
+	// The order stays in the order book until filled or cancelled.
+	NoOrderOption OrderOption = ""
+
+	// The order is either filled entirely at once, or cancelled.
+	FillOrKill OrderOption = "fillOrKill"
+
+	// Whatever can be filled at once is, and the rest is cancelled.
+	ImmediateOrCancel OrderOption = "immediateOrCancel"
+
+	// The order is cancelled instead of filling any order of the book, so that only maker fees are paid.
+	PostOnly OrderOption = "postOnly"
+)
+
+// ErrOrderTooSmall is returned when the total of an order is below the minimum allowed by Poloniex.
+var ErrOrderTooSmall = errors.New("poloniex: order total is below minimum")
+
+// DefaultMinOrderTotals are the minimum totals of an order allowed by Poloniex, by base currency.
+// Orders in markets whose base currency isn't listed are not checked.
+var DefaultMinOrderTotals = map[string]Decimal{
+	"BTC":  MustParseDecimal("0.0001"),
+	"ETH":  MustParseDecimal("0.0001"),
+	"XMR":  MustParseDecimal("0.0001"),
+	"USDT": MustParseDecimal("1"),
+}
+
+// WithMinOrderTotals overrides the minimum totals checked before placing an order, by base currency.
+func WithMinOrderTotals(minOrderTotals map[string]Decimal) Option {
+	return func(c *client) {
+		c.minOrderTotals = minOrderTotals
+	}
+}
+
+type ResultingTrade struct {
+	TradeID int64
+	Date    time.Time
+	Type    TradeSide
+	Rate    Decimal
+	Amount  Decimal
+	Total   Decimal
+}
+
+// OrderState tells whether an order is still in the order book.
+type OrderState string
+
+// Possible OrderState values.
+const (
+	OrderOpen            OrderState = "Open"
+	OrderPartiallyFilled OrderState = "Partially filled"
+
+	// The order is no longer in the order book, either filled or cancelled after some fills.
+	OrderClosed OrderState = "Closed"
+)
+
+type OrderStatus struct {
+	OrderNumber int64
+	Pair        CurrencyPair
+	State       OrderState
+	Type        TradeSide
+
+	// Rate and starting amount are only known while the order is in the order book,
+	// they are zero once it's closed.
+	Rate           Decimal
+	StartingAmount Decimal
+
+	// Date of the order, or of its first fill once it's closed.
+	Date time.Time
+
+	// Amount still in the order book, zero once the order is closed.
+	Remaining Decimal
+
+	// Sum of the amounts of Fills.
+	Filled Decimal
+
+	Fills []*MyTrade
+}
+
+type CancelResult struct {
+	OrderNumber int64
+
+	// Amount of the order which wasn't filled yet, and got cancelled.
+	Amount Decimal
+
+	// Message returned by Poloniex, ex: "Order #332042333440 canceled."
+	Message string
+}
+
+type OrderResult struct {
+	OrderNumber int64
+
+	// Trades made as soon as the order was placed.
+	ResultingTrades []*ResultingTrade
+
+	// Amount cancelled because it couldn't be filled at once, for immediate-or-cancel orders.
+	AmountUnfilled Decimal
+}
+
+// Rates and amounts of the trades resulting from an order are strings, converted to decimals in ResultingTrade.
+type resultingTradeFromJSON struct {
+	TradeID json.Number `json:"tradeID"`
+	Date    string      `json:"date"`
+	Type    string      `json:"type"`
+	Rate    string      `json:"rate"`
+	Amount  string      `json:"amount"`
+	Total   string      `json:"total"`
+}
+
+type orderResultFromJSON struct {
+	OrderNumber     json.Number               `json:"orderNumber"`
+	ResultingTrades []*resultingTradeFromJSON `json:"resultingTrades"`
+	AmountUnfilled  string                    `json:"amountUnfilled"`
+}
+
+type cancelResultFromJSON struct {
+	Success int    `json:"success"`
+	Amount  string `json:"amount"`
+	Message string `json:"message"`
+}
+
+// Unlike buy and sell, moveOrder and margin calls return the resulting trades by currency pair.
+type orderResultByPairFromJSON struct {
+	Success         int                                        `json:"success"`
+	Message         string                                     `json:"message"`
+	OrderNumber     json.Number                                `json:"orderNumber"`
+	ResultingTrades map[CurrencyPair][]*resultingTradeFromJSON `json:"resultingTrades"`
+}
+
+// flatten returns the order result with every resulting trade, whatever its currency pair.
+func (o *orderResultByPairFromJSON) flatten() *orderResultFromJSON {
+	r := &orderResultFromJSON{OrderNumber: o.OrderNumber}
+	for _, trades := range o.ResultingTrades {
+		r.ResultingTrades = append(r.ResultingTrades, trades...)
+	}
+
+	return r
+}
+
+// The response of returnOrderTrades has the same trades as the private returnTradeHistory,
+// with their currency pair, but without order number nor category.
+type orderTradeFromJSON struct {
+	myTradeFromJSON
+	CurrencyPair CurrencyPair `json:"currencyPair"`
+}
+
+// Poloniex returns the errors of returnOrderStatus inside the result, along with a success set to 0.
+type orderStatusResultFromJSON struct {
+	Success int             `json:"success"`
+	Result  json.RawMessage `json:"result"`
+}
+
+type orderStatusFromJSON struct {
+	Status         string       `json:"status"`
+	Rate           string       `json:"rate"`
+	Amount         string       `json:"amount"`
+	CurrencyPair   CurrencyPair `json:"currencyPair"`
+	Date           string       `json:"date"`
+	Total          string       `json:"total"`
+	Type           string       `json:"type"`
+	StartingAmount string       `json:"startingAmount"`
+}
+
+func (c *client) Buy(ctx context.Context, currencyPair CurrencyPair, rate, amount Decimal, option OrderOption) (*OrderResult, error) {
+	return c.placeOrder(ctx, "buy", currencyPair, rate, amount, option)
+}
+
+func (c *client) Sell(ctx context.Context, currencyPair CurrencyPair, rate, amount Decimal, option OrderOption) (*OrderResult, error) {
+	return c.placeOrder(ctx, "sell", currencyPair, rate, amount, option)
+}
+
+func (c *client) placeOrder(ctx context.Context, command string, currencyPair CurrencyPair, rate, amount Decimal, option OrderOption) (*OrderResult, error) {
+	if err := currencyPair.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := c.validateOrder(currencyPair, rate, amount); err != nil {
+		return nil, err
+	}
+
+	params := []postParam{
+		postParam{key: "currencyPair", value: currencyPair.String()},
+		postParam{key: "rate", value: rate.String()},
+		postParam{key: "amount", value: amount.String()},
+	}
+
+	switch option {
+	case NoOrderOption:
+	case FillOrKill, ImmediateOrCancel, PostOnly:
+		params = append(params, postParam{key: string(option), value: "1"})
+	default:
+		return nil, fmt.Errorf("poloniex: invalid order option %q", option)
+	}
+
+	o := &orderResultFromJSON{}
+
+	if err := c.tradeCall(ctx, command, o, params...); err != nil {
+		return nil, err
+	}
+
+	return c.convertOrderResult(command, o)
+}
+
+func (c *client) validateOrder(currencyPair CurrencyPair, rate, amount Decimal) error {
+	if rate.Sign() <= 0 {
+		return fmt.Errorf("poloniex: order rate must be positive, got %s", rate)
+	}
+
+	if amount.Sign() <= 0 {
+		return fmt.Errorf("poloniex: order amount must be positive, got %s", amount)
+	}
+
+	min, ok := c.minOrderTotals[currencyPair.Base()]
+	if !ok {
+		return nil
+	}
+
+	if total := rate.Mul(amount); total.LessThan(min) {
+		return fmt.Errorf("%w: %s %s, minimum is %s %s", ErrOrderTooSmall, total, currencyPair.Base(), min, currencyPair.Base())
+	}
+
+	return nil
+}
+
+func (c *client) convertOrderResult(command string, o *orderResultFromJSON) (*OrderResult, error) {
+	p := c.newRowParser(command)
+	row := p.row(fmt.Sprintf("order %s", o.OrderNumber))
+
+	orderResult := &OrderResult{
+		OrderNumber:     row.integer("orderNumber", o.OrderNumber.String()),
+		ResultingTrades: c.convertResultingTrades(p, o.ResultingTrades),
+	}
+
+	if o.AmountUnfilled != "" {
+		orderResult.AmountUnfilled = row.decimal("amountUnfilled", o.AmountUnfilled)
+	}
+
+	// The order was placed anyway, so parse errors are only reported.
+	p.report()
+
+	return orderResult, nil
+}
+
+func (c *client) convertResultingTrades(p *rowParser, trades []*resultingTradeFromJSON) []*ResultingTrade {
+	resultingTrades := []*ResultingTrade{}
+	for _, t := range trades {
+		row := p.row(fmt.Sprintf("trade %s", t.TradeID))
+
+		resultingTrade := &ResultingTrade{
+			TradeID: row.integer("tradeID", t.TradeID.String()),
+			Date:    row.date("date", t.Date),
+			Type:    row.side("type", t.Type),
+			Rate:    row.decimal("rate", t.Rate),
+			Amount:  row.decimal("amount", t.Amount),
+			Total:   row.decimal("total", t.Total),
+		}
+		if row.invalid {
+			continue
+		}
+
+		resultingTrades = append(resultingTrades, resultingTrade)
+	}
+
+	return resultingTrades
+}
+
+func (c *client) CancelOrder(ctx context.Context, orderNumber int64) (*CancelResult, error) {
+	r := &cancelResultFromJSON{}
+
+	if err := c.tradeCall(ctx, "cancelOrder", r, postParam{key: "orderNumber", value: fmt.Sprintf("%d", orderNumber)}); err != nil {
+		return nil, err
+	}
+
+	if r.Success != 1 {
+		return nil, newAPIError(http.StatusOK, "cancelOrder", r.Message)
+	}
+
+	p := c.newRowParser("cancelOrder")
+	row := p.row(fmt.Sprintf("order %d", orderNumber))
+
+	cancelResult := &CancelResult{
+		OrderNumber: orderNumber,
+		Message:     r.Message,
+	}
+
+	if r.Amount != "" {
+		cancelResult.Amount = row.decimal("amount", r.Amount)
+	}
+
+	// The order was cancelled anyway, so parse errors are only reported.
+	p.report()
+
+	return cancelResult, nil
+}
+
+func (c *client) MoveOrder(ctx context.Context, orderNumber int64, rate, amount Decimal, option OrderOption) (*OrderResult, error) {
+	if rate.Sign() <= 0 {
+		return nil, fmt.Errorf("poloniex: order rate must be positive, got %s", rate)
+	}
+
+	if amount.Sign() < 0 {
+		return nil, fmt.Errorf("poloniex: order amount can't be negative, got %s", amount)
+	}
+
+	params := []postParam{
+		postParam{key: "orderNumber", value: fmt.Sprintf("%d", orderNumber)},
+		postParam{key: "rate", value: rate.String()},
+	}
+	if !amount.IsZero() {
+		params = append(params, postParam{key: "amount", value: amount.String()})
+	}
+
+	switch option {
+	case NoOrderOption:
+	case ImmediateOrCancel, PostOnly:
+		params = append(params, postParam{key: string(option), value: "1"})
+	default:
+		return nil, fmt.Errorf("poloniex: order option %q is not supported when moving an order", option)
+	}
+
+	m := &orderResultByPairFromJSON{}
+
+	if err := c.tradeCall(ctx, "moveOrder", m, params...); err != nil {
+		return nil, err
+	}
+
+	if m.Success != 1 {
+		// The message tells why, ex: "Invalid order number, or you are not the person who placed the order."
+		message := m.Message
+		if message == "" {
+			message = fmt.Sprintf("unable to move order %d", orderNumber)
+		}
+
+		return nil, newAPIError(http.StatusOK, "moveOrder", message)
+	}
+
+	return c.convertOrderResult("moveOrder", m.flatten())
+}
+
+func (c *client) CancelAllOrders(ctx context.Context, currencyPair CurrencyPair) ([]*CancelResult, error) {
+	if currencyPair == AllPairs {
+		return nil, errors.New("poloniex: cancelling the orders of all markets at once is not supported")
+	}
+
+	if err := currencyPair.Validate(); err != nil {
+		return nil, err
+	}
+
+	// The orders aren't converted, so that an order with a value which can't be parsed is cancelled too.
+	o := []*openOrderFromJSON{}
+
+	if err := c.tradeCall(ctx, "returnOpenOrders", &o, postParam{key: "currencyPair", value: currencyPair.String()}); err != nil {
+		return nil, err
+	}
+
+	cancelResults := []*CancelResult{}
+	for _, v := range o {
+		cancelResult, err := c.CancelOrder(ctx, v.OrderNumber)
+
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.Kind == ErrorKindOrderNotFound {
+			continue
+		}
+
+		if err != nil {
+			return cancelResults, err
+		}
+
+		cancelResults = append(cancelResults, cancelResult)
+	}
+
+	return cancelResults, nil
+}
+
+func (c *client) GetOrderTrades(ctx context.Context, orderNumber int64) ([]*MyTrade, error) {
+	t := []*orderTradeFromJSON{}
+
+	if err := c.tradeCall(ctx, "returnOrderTrades", &t, postParam{key: "orderNumber", value: fmt.Sprintf("%d", orderNumber)}); err != nil {
+		return nil, err
+	}
+
+	p := c.newRowParser("returnOrderTrades")
+
+	trades := []*MyTrade{}
+	for _, v := range t {
+		v.OrderNumber = json.Number(fmt.Sprintf("%d", orderNumber))
+
+		trade, ok := c.convertMyTrade(p.row(fmt.Sprintf("%s trade %d", v.CurrencyPair, v.GlobalTradeID)), v.CurrencyPair, &v.myTradeFromJSON)
+		if !ok {
+			continue
+		}
+
+		trades = append(trades, trade)
+	}
+
+	if err := p.err(); err != nil {
+		return nil, err
+	}
+
+	return trades, nil
+}
+
+func (c *client) GetOrderStatus(ctx context.Context, orderNumber int64) (*OrderStatus, error) {
+	r := &orderStatusResultFromJSON{}
+
+	if err := c.tradeCall(ctx, "returnOrderStatus", r, postParam{key: "orderNumber", value: fmt.Sprintf("%d", orderNumber)}); err != nil {
+		return nil, err
+	}
+
+	// The order is not in the order book anymore, so its trades are the only thing left to look at.
+	if r.Success != 1 {
+		return c.getClosedOrderStatus(ctx, orderNumber)
+	}
+
+	statuses := make(map[string]*orderStatusFromJSON)
+	if err := json.Unmarshal(r.Result, &statuses); err != nil {
+		return nil, fmt.Errorf("poloniex: unable to decode returnOrderStatus response: %v", err)
+	}
+
+	s, ok := statuses[fmt.Sprintf("%d", orderNumber)]
+	if !ok {
+		return nil, newAPIError(http.StatusOK, "returnOrderStatus", fmt.Sprintf("order not found: %d", orderNumber))
+	}
+
+	p := c.newRowParser("returnOrderStatus")
+	row := p.row(fmt.Sprintf("order %d", orderNumber))
+
+	orderStatus := &OrderStatus{
+		OrderNumber:    orderNumber,
+		Pair:           s.CurrencyPair,
+		State:          OrderState(s.Status),
+		Type:           row.side("type", s.Type),
+		Rate:           row.decimal("rate", s.Rate),
+		StartingAmount: row.decimal("startingAmount", s.StartingAmount),
+		Date:           row.date("date", s.Date),
+		Remaining:      row.decimal("amount", s.Amount),
+		Fills:          []*MyTrade{},
+	}
+
+	if err := p.err(); err != nil {
+		return nil, err
+	}
+
+	if orderStatus.State == OrderOpen {
+		return orderStatus, nil
+	}
+
+	fills, err := c.GetOrderTrades(ctx, orderNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	orderStatus.Fills = fills
+	orderStatus.Filled = sumTradeAmounts(fills)
+
+	return orderStatus, nil
+}
+
+func (c *client) getClosedOrderStatus(ctx context.Context, orderNumber int64) (*OrderStatus, error) {
+	fills, err := c.GetOrderTrades(ctx, orderNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fills) == 0 {
+		return nil, newAPIError(http.StatusOK, "returnOrderTrades", fmt.Sprintf("order not found: %d", orderNumber))
+	}
+
+	first := fills[0]
+	for _, f := range fills {
+		if f.Date.Before(first.Date) {
+			first = f
+		}
+	}
+
+	return &OrderStatus{
+		OrderNumber: orderNumber,
+		Pair:        first.Pair,
+		State:       OrderClosed,
+		Type:        first.Type,
+		Date:        first.Date,
+		Filled:      sumTradeAmounts(fills),
+		Fills:       fills,
+	}, nil
+}
+
+func sumTradeAmounts(trades []*MyTrade) Decimal {
+	sum := Decimal{}
+	for _, t := range trades {
+		sum = sum.Add(t.Amount)
+	}
+
+	return sum
+}
@@ -0,0 +1,224 @@
+package poloniex
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBuy(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("buy", http.StatusOK, `{"orderNumber":31226040,"resultingTrades":[{"amount":"338.8732","date":"2014-10-18 23:03:21","rate":"0.00000173","total":"0.00058625","tradeID":"16164","type":"buy"}]}`)
+
+	r, err := newTestClient(s, WithParseMode(StrictParsing)).Buy(context.Background(), "BTC_NXT", MustParseDecimal("0.00000173"), MustParseDecimal("338.8732"), PostOnly)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	form := s.lastForm("buy")
+	if form["currencyPair"] != "BTC_NXT" || form["rate"] != "0.00000173" || form["amount"] != "338.8732" || form["postOnly"] != "1" {
+		t.Errorf("unexpected parameters %v", form)
+	}
+
+	if r.OrderNumber != 31226040 || len(r.ResultingTrades) != 1 {
+		t.Fatalf("unexpected result %+v", r)
+	}
+
+	trade := r.ResultingTrades[0]
+	if trade.TradeID != 16164 || trade.Type != BuySide || !trade.Total.Equal(MustParseDecimal("0.00058625")) ||
+		!trade.Date.Equal(time.Date(2014, 10, 18, 23, 3, 21, 0, time.UTC)) {
+		t.Errorf("unexpected trade %+v", trade)
+	}
+}
+
+func TestBuyInvalidResultingTrade(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("buy", http.StatusOK, `{"orderNumber":31226040,"resultingTrades":[{"amount":"338.8732","date":"2014-10-18 23:03:21","rate":"NaN","total":"0.00058625","tradeID":"16164","type":"buy"}]}`)
+
+	skipped := []*ParseError{}
+	c := newTestClient(s, WithParseMode(StrictParsing), WithSkippedRowHandler(func(e *ParseError) { skipped = append(skipped, e) }))
+
+	r, err := c.Buy(context.Background(), "BTC_NXT", MustParseDecimal("0.00000173"), MustParseDecimal("338.8732"), NoOrderOption)
+	if err != nil {
+		t.Fatalf("expected no error once the order is placed, got %v", err)
+	}
+
+	if r.OrderNumber != 31226040 || len(r.ResultingTrades) != 0 {
+		t.Errorf("unexpected result %+v", r)
+	}
+
+	if len(skipped) != 1 || skipped[0].Command != "buy" || skipped[0].Field != "rate" {
+		t.Errorf("unexpected skipped rows %v", skipped)
+	}
+}
+
+func TestBuyTooSmall(t *testing.T) {
+	s := newFakeServer(t)
+
+	_, err := newTestClient(s).Buy(context.Background(), "BTC_NXT", MustParseDecimal("0.00000001"), MustParseDecimal("1"), NoOrderOption)
+	if !errors.Is(err, ErrOrderTooSmall) {
+		t.Fatalf("expected ErrOrderTooSmall, got %v", err)
+	}
+
+	if n := s.callCount("buy"); n != 0 {
+		t.Errorf("expected no order to be sent, got %d", n)
+	}
+}
+
+func TestMoveOrder(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("moveOrder", http.StatusOK, `{"success":1,"orderNumber":"514851232549","resultingTrades":{"BTC_ETH":[{"amount":"0.1","date":"2018-05-01 10:00:00","rate":"0.08","total":"0.008","tradeID":"251834","type":"sell"}]}}`)
+
+	r, err := newTestClient(s, WithParseMode(StrictParsing)).MoveOrder(context.Background(), 514851026755, MustParseDecimal("0.08"), Decimal{}, NoOrderOption)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if r.OrderNumber != 514851232549 || len(r.ResultingTrades) != 1 || r.ResultingTrades[0].Type != SellSide {
+		t.Errorf("unexpected result %+v", r)
+	}
+
+	if form := s.lastForm("moveOrder"); form["orderNumber"] != "514851026755" || form["rate"] != "0.08" {
+		t.Errorf("unexpected parameters %v", form)
+	}
+}
+
+func TestMoveOrderFailure(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		message string
+		kind    ErrorKind
+	}{
+		{
+			name:    "order not found",
+			body:    `{"success":0,"message":"Invalid order number, or you are not the person who placed the order."}`,
+			message: "Invalid order number, or you are not the person who placed the order.",
+			kind:    ErrorKindOrderNotFound,
+		},
+		{
+			name:    "without message",
+			body:    `{"success":0}`,
+			message: "unable to move order 42",
+			kind:    ErrorKindUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newFakeServer(t)
+			s.respond("moveOrder", http.StatusOK, tt.body)
+
+			_, err := newTestClient(s).MoveOrder(context.Background(), 42, MustParseDecimal("0.08"), Decimal{}, NoOrderOption)
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected an APIError, got %v", err)
+			}
+
+			if apiErr.Message != tt.message || apiErr.Kind != tt.kind {
+				t.Errorf("unexpected error %+v", apiErr)
+			}
+		})
+	}
+}
+
+func TestCancelOrder(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("cancelOrder", http.StatusOK, `{"success":1,"amount":"50.00000000","message":"Order #332042333440 canceled."}`)
+
+	r, err := newTestClient(s).CancelOrder(context.Background(), 332042333440)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if r.OrderNumber != 332042333440 || !r.Amount.Equal(MustParseDecimal("50")) || r.Message != "Order #332042333440 canceled." {
+		t.Errorf("unexpected result %+v", r)
+	}
+}
+
+func TestCancelAllOrders(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnOpenOrders", http.StatusOK, `[{"orderNumber":"1","type":"buy","rate":"0.01","amount":"1","total":"0.01","date":"2018-05-01 10:00:00","margin":0},{"orderNumber":"2","type":"buy","rate":"0.01","amount":"2","total":"0.02","date":"2018-05-01 10:00:00","margin":0}]`)
+	s.respond("cancelOrder", http.StatusOK, `{"success":0,"message":"Invalid order number, or you are not the person who placed the order."}`)
+	s.respond("cancelOrder", http.StatusOK, `{"success":1,"amount":"2.00000000","message":"Order #2 canceled."}`)
+
+	results, err := newTestClient(s).CancelAllOrders(context.Background(), "BTC_ETH")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The first order was filled in the meantime, it's skipped.
+	if len(results) != 1 || results[0].OrderNumber != 2 {
+		t.Errorf("unexpected results %v", results)
+	}
+}
+
+func TestCancelAllOrdersInvalidOrder(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnOpenOrders", http.StatusOK, `[{"orderNumber":"1","type":"buy","rate":"bad","amount":"1","total":"0.01","date":"2018-05-01 10:00:00","margin":0},{"orderNumber":"2","type":"buy","rate":"0.01","amount":"2","total":"0.02","date":"2018-05-01 10:00:00","margin":0}]`)
+	s.respond("cancelOrder", http.StatusOK, `{"success":1,"amount":"1.00000000","message":"Order #1 canceled."}`)
+
+	results, err := newTestClient(s).CancelAllOrders(context.Background(), "BTC_ETH")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if n := s.callCount("cancelOrder"); n != 2 || len(results) != 2 {
+		t.Errorf("expected both orders to be cancelled, got %d calls and results %v", n, results)
+	}
+
+	if _, err := newTestClient(s).CancelAllOrders(context.Background(), AllPairs); err == nil {
+		t.Error("expected an error for all markets")
+	}
+}
+
+func TestGetOrderStatus(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnOrderStatus", http.StatusOK, `{"result":{"6071071":{"status":"Partially filled","rate":"0.04000000","amount":"0.90000000","currencyPair":"BTC_ETH","date":"2018-10-17 17:04:50","total":"0.03600000","type":"buy","startingAmount":"1.00000"}},"success":1}`)
+	s.respond("returnOrderTrades", http.StatusOK, `[{"globalTradeID":394131412,"tradeID":"5455033","currencyPair":"BTC_ETH","type":"buy","rate":"0.04000000","amount":"0.10000000","total":"0.00400000","fee":"0.00200000","date":"2018-10-17 17:05:01"}]`)
+
+	status, err := newTestClient(s, WithParseMode(StrictParsing)).GetOrderStatus(context.Background(), 6071071)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if status.State != OrderPartiallyFilled || status.Pair != "BTC_ETH" || status.Type != BuySide ||
+		!status.Remaining.Equal(MustParseDecimal("0.9")) || !status.StartingAmount.Equal(MustParseDecimal("1")) ||
+		!status.Filled.Equal(MustParseDecimal("0.1")) || len(status.Fills) != 1 {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	if f := status.Fills[0]; f.OrderNumber != 6071071 || f.Pair != "BTC_ETH" || f.TradeID != 5455033 {
+		t.Errorf("unexpected fill %+v", f)
+	}
+}
+
+func TestGetOrderStatusClosed(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnOrderStatus", http.StatusOK, `{"success":0,"result":{"error":"Order not found, or you are not the person who placed it."}}`)
+	s.respond("returnOrderTrades", http.StatusOK, `[{"globalTradeID":2,"tradeID":"12","currencyPair":"BTC_ETH","type":"sell","rate":"0.04","amount":"0.4","total":"0.016","fee":"0.0015","date":"2018-10-17 17:06:00"},{"globalTradeID":1,"tradeID":"11","currencyPair":"BTC_ETH","type":"sell","rate":"0.04","amount":"0.6","total":"0.024","fee":"0.0015","date":"2018-10-17 17:05:00"}]`)
+	s.respond("returnOrderTrades", http.StatusOK, `[]`)
+
+	c := newTestClient(s, WithParseMode(StrictParsing))
+
+	status, err := c.GetOrderStatus(context.Background(), 42)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if status.State != OrderClosed || status.Type != SellSide || !status.Filled.Equal(MustParseDecimal("1")) ||
+		!status.Date.Equal(time.Date(2018, 10, 17, 17, 5, 0, 0, time.UTC)) {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	// Without any trade, the order is unknown.
+	_, err = c.GetOrderStatus(context.Background(), 42)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Kind != ErrorKindOrderNotFound {
+		t.Fatalf("expected an order not found APIError, got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package poloniex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CurrencyPair identifies a market, as the base currency and the quote currency separated by an underscore.
+// Ex: "BTC_ETH" is the market where ETH is traded against BTC.
+type CurrencyPair string
+
+// AllPairs is accepted instead of a market by some calls, to get the result for every market at once.
+const AllPairs CurrencyPair = "all"
+
+var (
+	// ErrInvalidCurrencyPair is returned when a CurrencyPair is not formatted as "BASE_QUOTE".
+	ErrInvalidCurrencyPair = errors.New("poloniex: invalid currency pair")
+
+	// ErrUnknownCurrencyPair is returned by ValidateCurrencyPair when a CurrencyPair is not a Poloniex market.
+	ErrUnknownCurrencyPair = errors.New("poloniex: unknown currency pair")
+)
+
+var currencyPairRegexp = regexp.MustCompile(`^[A-Z0-9]+_[A-Z0-9]+$`)
+
+// NewCurrencyPair returns the CurrencyPair of the market where quote is traded against base.
+func NewCurrencyPair(base, quote string) CurrencyPair {
+	return CurrencyPair(strings.ToUpper(base) + "_" + strings.ToUpper(quote))
+}
+
+// ParseCurrencyPair parses a market name like "BTC_ETH", case insensitively.
+func ParseCurrencyPair(s string) (CurrencyPair, error) {
+	p := CurrencyPair(strings.ToUpper(strings.TrimSpace(s)))
+	if err := p.Validate(); err != nil {
+		return "", err
+	}
+
+	return p, nil
+}
+
+// Validate checks that p is formatted as "BASE_QUOTE".
+// It doesn't check that the market exists, see ValidateCurrencyPair for that.
+func (p CurrencyPair) Validate() error {
+	if !currencyPairRegexp.MatchString(string(p)) {
+		return fmt.Errorf("%w: %q, expected format is BASE_QUOTE, ex: BTC_ETH", ErrInvalidCurrencyPair, string(p))
+	}
+
+	return nil
+}
+
+// Base returns the base currency of p, ex: "BTC" for "BTC_ETH".
+func (p CurrencyPair) Base() string {
+	if i := strings.Index(string(p), "_"); i >= 0 {
+		return string(p[:i])
+	}
+
+	return ""
+}
+
+// Quote returns the quote currency of p, ex: "ETH" for "BTC_ETH".
+func (p CurrencyPair) Quote() string {
+	if i := strings.Index(string(p), "_"); i >= 0 {
+		return string(p[i+1:])
+	}
+
+	return ""
+}
+
+// Reverse returns p with its base and quote currencies swapped.
+func (p CurrencyPair) Reverse() CurrencyPair {
+	return NewCurrencyPair(p.Quote(), p.Base())
+}
+
+func (p CurrencyPair) String() string {
+	return string(p)
+}
+
+func (c *client) ValidateCurrencyPair(ctx context.Context, currencyPair CurrencyPair) error {
+	if err := currencyPair.Validate(); err != nil {
+		return err
+	}
+
+	tickers, err := c.GetTickers(ctx)
+	if err != nil {
+		return err
+	}
+
+	markets := make(map[CurrencyPair]bool, len(tickers))
+	for _, t := range tickers {
+		markets[t.Pair] = true
+	}
+
+	if markets[currencyPair] {
+		return nil
+	}
+
+	if markets[currencyPair.Reverse()] {
+		return fmt.Errorf("%w: %s, did you mean %s?", ErrUnknownCurrencyPair, currencyPair, currencyPair.Reverse())
+	}
+
+	// Give a more precise error when one of the currencies doesn't exist at all.
+	currencies, err := c.GetCurrencies(ctx)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]bool, len(currencies))
+	for _, cur := range currencies {
+		known[cur.Name] = true
+	}
+
+	for _, cur := range []string{currencyPair.Base(), currencyPair.Quote()} {
+		if !known[cur] {
+			return fmt.Errorf("%w: %s, currency %s doesn't exist", ErrUnknownCurrencyPair, currencyPair, cur)
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnknownCurrencyPair, currencyPair)
+}
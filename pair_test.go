@@ -0,0 +1,125 @@
+package poloniex
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestParseCurrencyPair(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected CurrencyPair
+		fails    bool
+	}{
+		{input: "BTC_ETH", expected: "BTC_ETH"},
+		{input: "btc_eth", expected: "BTC_ETH"},
+		{input: " usdt_btc ", expected: "USDT_BTC"},
+		{input: "BTC_STR2", expected: "BTC_STR2"},
+		{input: "BTCETH", fails: true},
+		{input: "BTC_", fails: true},
+		{input: "_ETH", fails: true},
+		{input: "BTC_ETH_LTC", fails: true},
+		{input: "BTC-ETH", fails: true},
+		{input: "", fails: true},
+	}
+
+	for _, tt := range tests {
+		p, err := ParseCurrencyPair(tt.input)
+		if tt.fails {
+			if !errors.Is(err, ErrInvalidCurrencyPair) {
+				t.Errorf("ParseCurrencyPair(%q): expected ErrInvalidCurrencyPair, got %v", tt.input, err)
+			}
+
+			continue
+		}
+
+		if err != nil || p != tt.expected {
+			t.Errorf("ParseCurrencyPair(%q) = %q, %v, expected %q", tt.input, p, err, tt.expected)
+		}
+	}
+}
+
+func TestCurrencyPairValidate(t *testing.T) {
+	tests := []struct {
+		pair  CurrencyPair
+		valid bool
+	}{
+		{pair: "BTC_ETH", valid: true},
+		{pair: "btc_eth"},
+		{pair: AllPairs},
+		{pair: ""},
+	}
+
+	for _, tt := range tests {
+		if err := tt.pair.Validate(); (err == nil) != tt.valid {
+			t.Errorf("%q.Validate() = %v, expected valid: %v", tt.pair, err, tt.valid)
+		}
+	}
+}
+
+func TestCurrencyPairAccessors(t *testing.T) {
+	tests := []struct {
+		pair    CurrencyPair
+		base    string
+		quote   string
+		reverse CurrencyPair
+	}{
+		{pair: "BTC_ETH", base: "BTC", quote: "ETH", reverse: "ETH_BTC"},
+		{pair: NewCurrencyPair("usdt", "btc"), base: "USDT", quote: "BTC", reverse: "BTC_USDT"},
+		{pair: "BTCETH", base: "", quote: "", reverse: "_"},
+	}
+
+	for _, tt := range tests {
+		if b, q, r := tt.pair.Base(), tt.pair.Quote(), tt.pair.Reverse(); b != tt.base || q != tt.quote || r != tt.reverse {
+			t.Errorf("%q: base %q, quote %q, reverse %q, expected %q, %q, %q", tt.pair, b, q, r, tt.base, tt.quote, tt.reverse)
+		}
+	}
+}
+
+func TestValidateCurrencyPair(t *testing.T) {
+	const (
+		tickers    = `{"BTC_ETH":{"id":148,"last":"0.07","lowestAsk":"0.0701","highestBid":"0.07","percentChange":"0.01","baseVolume":"10","quoteVolume":"142","isFrozen":"0","high24hr":"0.071","low24hr":"0.069"}}`
+		currencies = `{"BTC":{"id":28,"name":"Bitcoin","txFee":"0.0005","minConf":1,"disabled":0,"delisted":0,"frozen":0},"ETH":{"id":267,"name":"Ethereum","txFee":"0.005","minConf":35,"disabled":0,"delisted":0,"frozen":0},"LTC":{"id":125,"name":"Litecoin","txFee":"0.001","minConf":4,"disabled":0,"delisted":0,"frozen":0}}`
+	)
+
+	tests := []struct {
+		name    string
+		pair    CurrencyPair
+		err     error
+		message string
+	}{
+		{name: "known market", pair: "BTC_ETH"},
+		{name: "invalid format", pair: "btc_eth", err: ErrInvalidCurrencyPair},
+		{name: "reversed market", pair: "ETH_BTC", err: ErrUnknownCurrencyPair, message: "did you mean BTC_ETH?"},
+		{name: "unknown currency", pair: "BTC_XYZ", err: ErrUnknownCurrencyPair, message: "currency XYZ doesn't exist"},
+		{name: "unknown market", pair: "BTC_LTC", err: ErrUnknownCurrencyPair},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newFakeServer(t)
+			s.respond("returnTicker", http.StatusOK, tickers)
+			s.respond("returnCurrencies", http.StatusOK, currencies)
+
+			err := newTestClient(s).ValidateCurrencyPair(context.Background(), tt.pair)
+			if tt.err == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("expected %q in %q", tt.message, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package poloniex
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseMode tells the client what to do with the rows of a response which can't be parsed,
+// a balance which is not a number for example.
+type ParseMode int
+
+// Possible ParseMode values.
+const (
+	// LenientParsing skips invalid rows and returns the other ones.
+	// Skipped rows are reported to the handler set with WithSkippedRowHandler.
+	LenientParsing ParseMode = iota
+
+	// StrictParsing makes the call fail with a ParseErrors listing every invalid row.
+	// Calls which placed, moved, cancelled or closed something on Poloniex never fail because of a parse error,
+	// their invalid rows are reported as in lenient mode.
+	StrictParsing
+)
+
+// ParseError describes a value of a Poloniex response which couldn't be parsed.
+type ParseError struct {
+	// Command sent to Poloniex, ex: "returnBalances".
+	Command string
+
+	// Key identifies the row in the response, usually a currency pair or a currency.
+	Key string
+
+	// Name of the field which couldn't be parsed, ex: "amount".
+	Field string
+
+	// Value as returned by Poloniex.
+	Value string
+
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("poloniex: invalid %s %q for %s in %s response: %v", e.Field, e.Value, e.Key, e.Command, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseErrors is returned in strict mode when some rows of a response couldn't be parsed.
+type ParseErrors []*ParseError
+
+func (e ParseErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+
+	return fmt.Sprintf("poloniex: %d invalid values: %s", len(e), strings.Join(msgs, "; "))
+}
+
+func (e ParseErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// WithParseMode sets how the client handles rows which can't be parsed.
+// LenientParsing is the default.
+func WithParseMode(mode ParseMode) Option {
+	return func(c *client) {
+		c.parseMode = mode
+	}
+}
+
+// WithSkippedRowHandler sets the function called with every row skipped in lenient mode.
+// By default, skipped rows are logged as warnings.
+func WithSkippedRowHandler(handler func(*ParseError)) Option {
+	return func(c *client) {
+		c.skippedRowHandler = handler
+	}
+}
+
+// isEmptyJSONArray reports whether raw is "[]",
+// which Poloniex returns instead of an empty map for some calls.
+func isEmptyJSONArray(raw json.RawMessage) bool {
+	return string(bytes.TrimSpace(raw)) == "[]"
+}
+
+// rowParser collects the parse errors of a response.
+type rowParser struct {
+	c       *client
+	command string
+	errs    ParseErrors
+}
+
+func (c *client) newRowParser(command string) *rowParser {
+	return &rowParser{c: c, command: command}
+}
+
+// row starts the parsing of a new row of the response, identified by key.
+func (p *rowParser) row(key string) *parsedRow {
+	return &parsedRow{p: p, key: key}
+}
+
+// err returns the parse errors in strict mode.
+// In lenient mode, errors are reported to the skipped row handler and nil is returned.
+func (p *rowParser) err() error {
+	if len(p.errs) == 0 {
+		return nil
+	}
+
+	if p.c.parseMode == StrictParsing {
+		return p.errs
+	}
+
+	p.report()
+
+	return nil
+}
+
+// report sends the parse errors to the skipped row handler, whatever the parse mode.
+// It's used once Poloniex has performed the action, so that the call doesn't look like it failed.
+func (p *rowParser) report() {
+	for _, e := range p.errs {
+		if p.c.skippedRowHandler != nil {
+			p.c.skippedRowHandler(e)
+
+			continue
+		}
+
+		p.c.logger.Warnf("skipping row: %v", e)
+	}
+}
+
+// parsedRow parses the fields of a row, and remembers if one of them was invalid.
+type parsedRow struct {
+	p       *rowParser
+	key     string
+	invalid bool
+}
+
+func (r *parsedRow) fail(field, value string, err error) {
+	r.invalid = true
+	r.p.errs = append(r.p.errs, &ParseError{
+		Command: r.p.command,
+		Key:     r.key,
+		Field:   field,
+		Value:   value,
+		Err:     err,
+	})
+}
+
+func (r *parsedRow) decimal(field, value string) Decimal {
+	d, err := ParseDecimal(value)
+	if err != nil {
+		r.fail(field, value, err)
+	}
+
+	return d
+}
+
+// jsonDecimal parses a value which might either be a JSON string or a JSON number.
+func (r *parsedRow) jsonDecimal(field string, value json.RawMessage) Decimal {
+	var d Decimal
+	if err := json.Unmarshal(value, &d); err != nil {
+		r.fail(field, string(value), err)
+	}
+
+	return d
+}
+
+func (r *parsedRow) integer(field, value string) int64 {
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		r.fail(field, value, err)
+	}
+
+	return i
+}
+
+func (r *parsedRow) date(field, value string) time.Time {
+	t, err := parseDate(value)
+	if err != nil {
+		r.fail(field, value, err)
+	}
+
+	return t
+}
+
+func (r *parsedRow) side(field, value string) TradeSide {
+	s, err := ParseTradeSide(value)
+	if err != nil {
+		r.fail(field, value, err)
+	}
+
+	return s
+}
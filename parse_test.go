@@ -0,0 +1,37 @@
+package poloniex
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestGetTickersInvalidRow(t *testing.T) {
+	const body = `{"BTC_LTC":{"id":50,"last":"0.0251","lowestAsk":"0.02589999","highestBid":"0.0251","percentChange":"0.02390438","baseVolume":"6.16485315","quoteVolume":"245.82513926","isFrozen":"0","high24hr":"0.026","low24hr":"0.0241"},"BTC_NXT":{"id":69,"last":"NaN","lowestAsk":"0.0000571","highestBid":"0.00004903","percentChange":"0.1670157","baseVolume":"0.45347489","quoteVolume":"9094","isFrozen":"0","high24hr":"0.000058","low24hr":"0.000048"}}`
+
+	s := newFakeServer(t)
+	s.respond("returnTicker", http.StatusOK, body)
+	s.respond("returnTicker", http.StatusOK, body)
+
+	skipped := []*ParseError{}
+	tickers, err := newTestClient(s, WithSkippedRowHandler(func(e *ParseError) { skipped = append(skipped, e) })).GetTickers(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error in lenient mode: %v", err)
+	}
+
+	if len(tickers) != 1 || tickers[0].Pair != "BTC_LTC" {
+		t.Errorf("expected only BTC_LTC, got %v", tickers)
+	}
+
+	if len(skipped) != 1 || skipped[0].Key != "BTC_NXT" || skipped[0].Field != "last" || skipped[0].Value != "NaN" {
+		t.Errorf("unexpected skipped rows %v", skipped)
+	}
+
+	_, err = newTestClient(s, WithParseMode(StrictParsing)).GetTickers(context.Background())
+
+	var parseErrs ParseErrors
+	if !errors.As(err, &parseErrs) || len(parseErrs) != 1 {
+		t.Fatalf("expected a ParseErrors in strict mode, got %v", err)
+	}
+}
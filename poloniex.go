@@ -1,5 +1,11 @@
 package poloniex
 
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
 // Poloniex endpoints.
 const (
 	URL       = "https://poloniex.com"
@@ -8,55 +14,166 @@ const (
 )
 
 // Poloniex is the public interface to interact with Poloniex API.
+// Every call takes a context whose cancellation and deadline are propagated to the underlying HTTP request.
 type Poloniex interface {
+	// Margin trading calls.
+	Margin
+
+	// Lending calls.
+	Lending
+
 	//////////////////
 	// Public calls //
 	//////////////////
 
 	// Returns the ticker for all markets.
-	// Ex: map["BTC_EXP"] == {ID: 123, Last:"456", etc.}
-	GetTickers() ([]*Ticker, error)
+	// Ex: {Pair: "BTC_EXP", ID: 123, Last: 0.00000456, etc.}
+	GetTickers(ctx context.Context) ([]*Ticker, error)
 
 	// Returns the 24-hour volume for all markets, plus totals for primary currencies.
-	Get24hVolume() (*Volume24h, error)
+	Get24hVolume(ctx context.Context) (*Volume24h, error)
 
 	// Returns the order book for a given market, as well as a sequence number for use with the Push API,
 	// and an indicator specifying whether the market is frozen.
-	GetOrderBook(currencyPair string, depth uint) (*OrderBook, error)
+	GetOrderBook(ctx context.Context, currencyPair CurrencyPair, depth uint) (*OrderBook, error)
 
 	// Returns the order book of all markets, as well as a sequence number for use with the Push API,
 	// and an indicator specifying whether the market is frozen.
-	GetAllOrderBooks(depth uint) ([]*OrderBook, error)
+	GetAllOrderBooks(ctx context.Context, depth uint) ([]*OrderBook, error)
 
-	// Returns the past 200 trades for a given market, or up to 50,000 trades between a range specified in UNIX timestamps,
+	// Returns the past 200 trades for a given market, or up to 50,000 trades between a range specified
 	// by the "start" and "end" GET parameters.
-	// If start or end are set to 0, it returns the past 200 trades.
-	GetTradeHistory(currencyPair string, start, end uint64) ([]*TradeHistory, error)
+	// If start or end is the zero time, it returns the past 200 trades.
+	GetTradeHistory(ctx context.Context, currencyPair CurrencyPair, start, end time.Time) ([]*TradeHistory, error)
 
 	// Returns candlestick chart data. Required GET parameters are "currencyPair",
 	// "period" (candlestick period in seconds; valid values are 300, 900, 1800, 7200, 14400, and 86400),
 	// "start", and "end". "Start" and "end" are given in UNIX timestamp format and used to specify the date range for the data returned.
-	GetChartData(currencyPair string, start, end uint64, period ChartDataPeriod) ([]*ChartData, error)
+	GetChartData(ctx context.Context, currencyPair CurrencyPair, start, end uint64, period ChartDataPeriod) ([]*ChartData, error)
 
 	// Returns information about currencies.
-	GetCurrencies() ([]*Currency, error)
+	GetCurrencies(ctx context.Context) ([]*Currency, error)
 
 	// Returns the list of loan offers and demands for a given currency, specified by the "currency" GET parameter.
-	GetLoanOrders(currency string) (*LoanOrders, error)
+	GetLoanOrders(ctx context.Context, currency string) (*LoanOrders, error)
+
+	// Checks that the given currency pair is well formatted and is an existing market.
+	// It returns an error wrapping ErrUnknownCurrencyPair otherwise, suggesting the reversed pair if it exists.
+	ValidateCurrencyPair(ctx context.Context, currencyPair CurrencyPair) error
 
 	///////////////////
 	// Private calls //
 	///////////////////
 
 	// Returns all of your available balances.
-	GetBalances() ([]*Balance, error)
+	GetBalances(ctx context.Context) ([]*Balance, error)
 
 	// Returns all of your balances, including available balance, balance on orders, and the estimated BTC value of your balance.
-	GetCompleteBalances(account BalanceAccount) ([]*CompleteBalance, error)
+	GetCompleteBalances(ctx context.Context, account BalanceAccount) ([]*CompleteBalance, error)
+
+	// Returns all of your deposit addresses, by currency.
+	// Ex: map["BTC"] == "19YqztHmspv2egyD6jQM3yn81x5t5krVdJ"
+	GetDepositAddresses(ctx context.Context) (map[string]string, error)
+
+	// Generates a new deposit address for the given currency.
+	// If Poloniex doesn't generate one, because the previous address is still unused for example,
+	// the returned GeneratedAddress isn't flagged as Generated and holds the reason in Response.
+	GenerateNewAddress(ctx context.Context, currency string) (*GeneratedAddress, error)
+
+	// Returns your deposit and withdrawal history within a range, specified by the "start" and "end" POST parameters.
+	// Long ranges are split into several calls, so a whole year can be fetched at once.
+	GetDepositsWithdrawals(ctx context.Context, start, end time.Time) (*DepositsWithdrawals, error)
+
+	// Returns your open orders for a given market.
+	GetOpenOrders(ctx context.Context, currencyPair CurrencyPair) ([]*OpenOrder, error)
+
+	// Returns your open orders of all markets.
+	GetAllOpenOrders(ctx context.Context) ([]*OpenOrder, error)
+
+	// Returns your trade history for a given market, or for all markets with AllPairs,
+	// within a range specified by "start" and "end", most recent first.
+	// Unlike GetTradeHistory, it includes order numbers, fees and categories.
+	// Poloniex returns at most 10,000 trades per call, more are fetched transparently up to limit.
+	// A limit of 0 returns every trade of the range.
+	// If start and end are both the zero time, it returns the trades of the past day.
+	// If only end is zero, it defaults to now. An end without start is rejected.
+	GetMyTradeHistory(ctx context.Context, currencyPair CurrencyPair, start, end time.Time, limit int) ([]*MyTrade, error)
+
+	// Places a limit buy order in a given market, at the given rate for the given amount of the quote currency.
+	// The execution option can be used to make the order fill-or-kill, immediate-or-cancel or post-only.
+	// Orders whose total is below the minimum allowed by Poloniex are rejected before being sent.
+	// Once the order is placed, values of the result which can't be parsed are reported to the skipped row handler
+	// instead of failing the call, whatever the parse mode.
+	Buy(ctx context.Context, currencyPair CurrencyPair, rate, amount Decimal, option OrderOption) (*OrderResult, error)
+
+	// Places a limit sell order in a given market. Parameters and output are the same as for Buy.
+	Sell(ctx context.Context, currencyPair CurrencyPair, rate, amount Decimal, option OrderOption) (*OrderResult, error)
+
+	// Cancels one of your open orders.
+	// If the order is unknown, already filled or cancelled, the returned APIError is of kind ErrorKindOrderNotFound.
+	CancelOrder(ctx context.Context, orderNumber int64) (*CancelResult, error)
+
+	// Cancels an order and places a new one of the same type in a single atomic transaction,
+	// meaning either both operations will succeed or both will fail.
+	// If amount is zero, the remaining amount of the order is kept.
+	// Only PostOnly and ImmediateOrCancel options are supported.
+	// As for Buy, the call doesn't fail once the order is moved because of a value which can't be parsed.
+	MoveOrder(ctx context.Context, orderNumber int64, rate, amount Decimal, option OrderOption) (*OrderResult, error)
+
+	// Cancels all your open orders in a given market.
+	// Orders filled in the meantime are ignored. On failure, the orders already cancelled are returned along with the error.
+	// Every open order is cancelled, even one which GetOpenOrders would skip because of a value which can't be parsed.
+	CancelAllOrders(ctx context.Context, currencyPair CurrencyPair) ([]*CancelResult, error)
+
+	// Returns all trades involving a given order.
+	// If the order has no trade, the returned APIError is of kind ErrorKindOrderNotFound.
+	GetOrderTrades(ctx context.Context, orderNumber int64) ([]*MyTrade, error)
+
+	// Returns the status of a given order: whether it's still open, its remaining amount and its fills.
+	// Orders no longer in the order book are only known through their trades,
+	// so an order cancelled before any fill is reported as not found.
+	GetOrderStatus(ctx context.Context, orderNumber int64) (*OrderStatus, error)
+
+	// Withdraws the given amount of a currency to the given address.
+	// For currencies needing one, paymentID is sent along, otherwise leave it empty.
+	// If a WithdrawalPolicy is set, it's enforced before the request is signed,
+	// and an error wrapping ErrWithdrawalBlocked is returned if the withdrawal isn't allowed.
+	// Withdrawals are never retried, even with WithTradeRetryPolicy.
+	Withdraw(ctx context.Context, currency string, amount Decimal, address, paymentID string) (*WithdrawResult, error)
+
+	// Returns your current trading fees and trailing 30-day volume in BTC.
+	GetFeeInfo(ctx context.Context) (*FeeInfo, error)
+
+	// Returns your balances sorted by account: exchange, margin and lending.
+	// Use AllAccounts to get every account, or one of ExchangeAccount, MarginAccount and LendingAccount.
+	// Accounts without any balance are missing from the result.
+	GetAvailableAccountBalances(ctx context.Context, account BalanceAccount) (map[BalanceAccount][]*Balance, error)
+
+	// Transfers funds from one account to another, e.g. from your exchange account to your margin account.
+	TransferBalance(ctx context.Context, currency string, amount Decimal, from, to BalanceAccount) (*TransferResult, error)
 }
 
 type Ticker struct {
-	Currency      string
+	// Market of the ticker, ex: "BTC_ETH".
+	Pair CurrencyPair
+
+	ID            int64
+	Last          Decimal
+	LowestAsk     Decimal
+	HighestBid    Decimal
+	PercentChange Decimal
+	BaseVolume    Decimal
+	QuoteVolume   Decimal
+	IsFrozen      bool
+	High24hr      Decimal
+	Low24hr       Decimal
+
+	// Values as returned by Poloniex, for auditing.
+	Raw RawTicker
+}
+
+// RawTicker is a ticker as returned by Poloniex, with every value as a string.
+type RawTicker struct {
 	ID            int64  `json:"id"`
 	Last          string `json:"last"`
 	LowestAsk     string `json:"lowestAsk"`
@@ -69,6 +186,16 @@ type Ticker struct {
 	Low24hr       string `json:"low24hr"`
 }
 
+// Spread returns the difference between the lowest ask and the highest bid.
+func (t *Ticker) Spread() Decimal {
+	return t.LowestAsk.Sub(t.HighestBid)
+}
+
+// MidPrice returns the price halfway between the lowest ask and the highest bid.
+func (t *Ticker) MidPrice() Decimal {
+	return t.LowestAsk.Add(t.HighestBid).Mul(NewDecimal(5, -1))
+}
+
 // Volume24h represents the response of the Return24hVolume API call.
 type Volume24h struct {
 	// Contains total volume for some primary currencies.
@@ -79,36 +206,55 @@ type Volume24h struct {
 	// - totalUSDT
 	// - totalXMR
 	// - totalXUSD
-	PrimaryCurrenciesTotals map[string]string
+	PrimaryCurrenciesTotals map[string]Decimal
 
 	// This map contains volumes by market.
 	// Example: For the market BTC_LTC, it gives:
 	// map["BTC_LTC"]["BTC"] == "2.23248854".
 	// map["BTC_LTC"]["LTC"] == "87.10381314".
-	Markets map[string]map[string]string
+	Markets map[CurrencyPair]map[string]Decimal
 }
 
 type Order struct {
-	Value  float64
-	Amount float64
+	Value  Decimal
+	Amount Decimal
 }
 
 type OrderBook struct {
-	Pair     string
+	Pair     CurrencyPair
 	Asks     []*Order
 	Bids     []*Order
 	IsFrozen string
 	Seq      int64
 }
 
+// TradeSide tells whether a trade or an order buys or sells the quote currency of a market.
+type TradeSide string
+
+// Possible TradeSide values.
+const (
+	BuySide  TradeSide = "buy"
+	SellSide TradeSide = "sell"
+)
+
+// ParseTradeSide parses "buy" or "sell" into a TradeSide.
+func ParseTradeSide(s string) (TradeSide, error) {
+	switch side := TradeSide(s); side {
+	case BuySide, SellSide:
+		return side, nil
+	default:
+		return "", fmt.Errorf("poloniex: invalid trade side %q", s)
+	}
+}
+
 type TradeHistory struct {
 	GlobalTradeID int64
 	TradeID       int64
-	Date          string
-	Type          string
-	Rate          float64
-	Amount        float64
-	Total         float64
+	Date          time.Time
+	Type          TradeSide
+	Rate          Decimal
+	Amount        Decimal
+	Total         Decimal
 }
 
 type ChartDataPeriod int64
@@ -124,29 +270,29 @@ const (
 
 type ChartData struct {
 	Date            int64   `json:"date"`
-	High            float64 `json:"high"`
-	Low             float64 `json:"low"`
-	Open            float64 `json:"open"`
-	Close           float64 `json:"close"`
-	Volume          float64 `json:"volume"`
-	QuoteVolume     float64 `json:"quoteVolume"`
-	WeightedAverage float64 `json:"weightedAverage"`
+	High            Decimal `json:"high"`
+	Low             Decimal `json:"low"`
+	Open            Decimal `json:"open"`
+	Close           Decimal `json:"close"`
+	Volume          Decimal `json:"volume"`
+	QuoteVolume     Decimal `json:"quoteVolume"`
+	WeightedAverage Decimal `json:"weightedAverage"`
 }
 
 type Currency struct {
-	ID             int64  `json:"id"`
-	Name           string `json:"name"`
-	TxFee          string `json:"txFee"`
-	MinConf        int    `json:"minConf"`
-	DepositAddress string `json:"depositAddress"`
-	Disabled       int    `json:"disabled"`
-	Delisted       int    `json:"delisted"`
-	Frozen         int    `json:"frozen"`
+	ID             int64   `json:"id"`
+	Name           string  `json:"name"`
+	TxFee          Decimal `json:"txFee"`
+	MinConf        int     `json:"minConf"`
+	DepositAddress string  `json:"depositAddress"`
+	Disabled       int     `json:"disabled"`
+	Delisted       int     `json:"delisted"`
+	Frozen         int     `json:"frozen"`
 }
 
 type Loan struct {
-	Rate     float64
-	Amount   float64
+	Rate     Decimal
+	Amount   Decimal
 	RangeMin int64
 	RangeMax int64
 }
@@ -158,7 +304,7 @@ type LoanOrders struct {
 
 type Balance struct {
 	Currency string
-	Amount   float64
+	Amount   Decimal
 }
 
 type BalanceAccount string
@@ -166,13 +312,109 @@ type BalanceAccount string
 const (
 	AllAccounts         BalanceAccount = "all"
 	ExchangeAccountOnly BalanceAccount = ""
+
+	// Accounts funds can be transferred between.
+	ExchangeAccount BalanceAccount = "exchange"
+	MarginAccount   BalanceAccount = "margin"
+	LendingAccount  BalanceAccount = "lending"
 )
 
+// IsTransferable reports whether funds can be transferred from or to a.
+func (a BalanceAccount) IsTransferable() bool {
+	return a == ExchangeAccount || a == MarginAccount || a == LendingAccount
+}
+
 type CompleteBalance struct {
 	Currency  string
-	Available float64
-	OnOrders  float64
-	BTCValue  float64
+	Available Decimal
+	OnOrders  Decimal
+	BTCValue  Decimal
+}
+
+type TransferResult struct {
+	// Message returned by Poloniex, ex: "Transferred 2 BTC from exchange to margin account."
+	Message string
+}
+
+type GeneratedAddress struct {
+	Currency string
+
+	// New deposit address, empty if none was generated.
+	Address string
+
+	// False when Poloniex didn't generate a new address.
+	Generated bool
+
+	// Response returned by Poloniex, either the new address or the reason why none was generated.
+	Response string
+}
+
+type Deposit struct {
+	Currency      string
+	Address       string
+	Amount        Decimal
+	Confirmations int64
+	TxID          string
+	Date          time.Time
+	Status        string
+}
+
+type Withdrawal struct {
+	WithdrawalNumber int64
+	Currency         string
+	Address          string
+	Amount           Decimal
+	Fee              Decimal
+	Date             time.Time
+
+	// Status without the transaction ID, ex: "COMPLETE".
+	Status string
+
+	// Transaction ID, only known once the withdrawal is complete.
+	TxID string
+
+	IPAddress string
+}
+
+type DepositsWithdrawals struct {
+	Deposits    []*Deposit
+	Withdrawals []*Withdrawal
+}
+
+type OpenOrder struct {
+	Pair           CurrencyPair
+	OrderNumber    int64
+	Type           TradeSide
+	Rate           Decimal
+	Amount         Decimal
+	StartingAmount Decimal
+	Total          Decimal
+	Date           time.Time
+	Margin         bool
+}
+
+// TradeCategory tells which account a trade was made with.
+type TradeCategory string
+
+// Possible TradeCategory values.
+const (
+	ExchangeCategory    TradeCategory = "exchange"
+	MarginTradeCategory TradeCategory = "marginTrade"
+	SettlementCategory  TradeCategory = "settlement"
+)
+
+type MyTrade struct {
+	Pair          CurrencyPair
+	GlobalTradeID int64
+	TradeID       int64
+	OrderNumber   int64
+	Date          time.Time
+	Type          TradeSide
+	Rate          Decimal
+	Amount        Decimal
+	Total         Decimal
+	Fee           Decimal
+	Category      TradeCategory
 }
 
 // TradeCommand is an alias to string representing private calls to poloniex API.
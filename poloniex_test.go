@@ -0,0 +1,28 @@
+package poloniex
+
+import "testing"
+
+func TestTickerSpreadAndMidPrice(t *testing.T) {
+	tests := []struct {
+		lowestAsk  string
+		highestBid string
+		spread     string
+		midPrice   string
+	}{
+		{lowestAsk: "0.02589999", highestBid: "0.0251", spread: "0.00079999", midPrice: "0.025499995"},
+		{lowestAsk: "0.00000173", highestBid: "0.00000172", spread: "0.00000001", midPrice: "0.000001725"},
+		{lowestAsk: "6500", highestBid: "6500", spread: "0", midPrice: "6500"},
+	}
+
+	for _, tt := range tests {
+		ticker := &Ticker{LowestAsk: MustParseDecimal(tt.lowestAsk), HighestBid: MustParseDecimal(tt.highestBid)}
+
+		if spread := ticker.Spread(); !spread.Equal(MustParseDecimal(tt.spread)) {
+			t.Errorf("spread of %s/%s = %s, expected %s", tt.lowestAsk, tt.highestBid, spread, tt.spread)
+		}
+
+		if mid := ticker.MidPrice(); !mid.Equal(MustParseDecimal(tt.midPrice)) {
+			t.Errorf("mid price of %s/%s = %s, expected %s", tt.lowestAsk, tt.highestBid, mid, tt.midPrice)
+		}
+	}
+}
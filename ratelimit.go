@@ -0,0 +1,130 @@
+package poloniex
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultRequestsPerSecond is the number of calls per second allowed by Poloniex before banning an IP.
+const DefaultRequestsPerSecond = 6
+
+// ErrRateLimitExceeded is returned instead of waiting when a fail fast RateLimiter has no token left.
+var ErrRateLimitExceeded = errors.New("poloniex: client side rate limit exceeded")
+
+// RateLimiter is a token bucket limiting the number of requests sent to Poloniex.
+// The same RateLimiter can be shared by several clients, and is safe for concurrent use.
+type RateLimiter struct {
+	mu       sync.Mutex
+	rate     float64
+	burst    float64
+	tokens   float64
+	last     time.Time
+	failFast bool
+}
+
+// NewRateLimiter returns a RateLimiter allowing requestsPerSecond requests per second,
+// with bursts of up to burst requests.
+// When failFast is true, Wait returns ErrRateLimitExceeded instead of blocking until a token is available.
+func NewRateLimiter(requestsPerSecond float64, burst int, failFast bool) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		rate:     requestsPerSecond,
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		last:     time.Now(),
+		failFast: failFast,
+	}
+}
+
+// Wait takes a token from the bucket, blocking until one is available or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		delay, err := l.reserve()
+		if err != nil || delay == 0 {
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes a token if one is available.
+// Otherwise, it returns how long to wait before trying again.
+func (l *RateLimiter) reserve() (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+
+		return 0, nil
+	}
+
+	if l.failFast {
+		return 0, ErrRateLimitExceeded
+	}
+
+	if l.rate <= 0 {
+		return 0, errors.New("poloniex: rate limiter does not allow any request")
+	}
+
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second)), nil
+}
+
+// WithRateLimiter sets the limiter shared by all public and trading calls.
+// By default, they share a limiter allowing DefaultRequestsPerSecond,
+// as Poloniex counts every request sent from the same IP.
+// A nil limiter disables the limit.
+func WithRateLimiter(l *RateLimiter) Option {
+	return func(c *client) {
+		c.publicLimiter = l
+		c.tradeLimiter = l
+	}
+}
+
+// WithPublicRateLimiter sets the limiter of public calls only,
+// to limit them separately from trading calls.
+// Make sure the sum of both limits stays under DefaultRequestsPerSecond.
+// A nil limiter disables the limit.
+func WithPublicRateLimiter(l *RateLimiter) Option {
+	return func(c *client) {
+		c.publicLimiter = l
+	}
+}
+
+// WithTradeRateLimiter sets the limiter of trading calls only,
+// to limit them separately from public calls.
+// Make sure the sum of both limits stays under DefaultRequestsPerSecond.
+// A nil limiter disables the limit.
+func WithTradeRateLimiter(l *RateLimiter) Option {
+	return func(c *client) {
+		c.tradeLimiter = l
+	}
+}
+
+func waitRateLimiter(ctx context.Context, l *RateLimiter) error {
+	if l == nil {
+		return nil
+	}
+
+	return l.Wait(ctx)
+}
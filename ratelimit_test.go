@@ -0,0 +1,59 @@
+package poloniex
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestDefaultRateLimiterIsShared(t *testing.T) {
+	c := New("key", "secret").(*client)
+
+	if c.publicLimiter == nil || c.publicLimiter != c.tradeLimiter {
+		t.Fatal("expected public and trading calls to share the default limiter")
+	}
+
+	if c.publicLimiter.rate != DefaultRequestsPerSecond {
+		t.Errorf("expected %d requests per second, got %v", DefaultRequestsPerSecond, c.publicLimiter.rate)
+	}
+}
+
+func TestSharedRateLimiter(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnTicker", http.StatusOK, `{}`)
+	s.respond("returnBalances", http.StatusOK, `{}`)
+
+	c := newTestClient(s, WithRateLimiter(NewRateLimiter(0.001, 1, true)))
+
+	if _, err := c.GetTickers(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := c.GetBalances(context.Background()); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Fatalf("expected ErrRateLimitExceeded, got %v", err)
+	}
+}
+
+func TestSeparateRateLimiters(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnTicker", http.StatusOK, `{}`)
+	s.respond("returnBalances", http.StatusOK, `{}`)
+
+	c := newTestClient(s,
+		WithPublicRateLimiter(NewRateLimiter(0.001, 1, true)),
+		WithTradeRateLimiter(NewRateLimiter(0.001, 1, true)),
+	)
+
+	if _, err := c.GetTickers(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := c.GetBalances(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := c.GetTickers(context.Background()); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Fatalf("expected ErrRateLimitExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package poloniex
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy describes how failed calls are retried.
+type RetryPolicy struct {
+	// Maximum number of attempts, including the first one.
+	MaxAttempts int
+
+	// Delay before the first retry, doubled on each following retry.
+	BaseDelay time.Duration
+
+	// Upper bound of the delay between two attempts.
+	MaxDelay time.Duration
+
+	// Retryable reports whether a call failing with the given error should be retried.
+	// IsRetryable is used when nil.
+	Retryable func(error) bool
+}
+
+// DefaultRetryPolicy is applied to public calls unless overridden with WithRetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+	Retryable:   IsRetryable,
+}
+
+// IsRetryable reports whether err is likely to be transient:
+// 5xx responses, HTML error pages, rate limiting and connection failures.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500 ||
+			apiErr.Kind == ErrorKindRateLimited ||
+			apiErr.Kind == ErrorKindUnexpectedResponse
+	}
+
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// delay returns the jittered delay to wait before the given retry, starting at 1.
+func (p *RetryPolicy) delay(retry int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < retry && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+
+	if d <= 0 {
+		return 0
+	}
+
+	// Wait between half and the whole delay,
+	// so that concurrent callers don't retry all at once.
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func (c *client) retry(ctx context.Context, policy *RetryPolicy, command string, call func() error) error {
+	if policy == nil {
+		return call()
+	}
+
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = call()
+		if err == nil || attempt >= policy.MaxAttempts || !retryable(err) {
+			return err
+		}
+
+		d := policy.delay(attempt)
+		c.logger.Infof("%s failed, retrying in %v: %v", command, d, err)
+
+		timer := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			// Prefer the context error so callers can check it with errors.Is.
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// WithRetryPolicy sets the retry policy of public calls, which are all idempotent.
+// A nil policy disables retries.
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(c *client) {
+		c.publicRetry = policy
+	}
+}
+
+// WithTradeRetryPolicy opts in for retrying trading calls.
+// Use it with care: a retried trading call, like an order placement,
+// might have been processed by Poloniex even though it failed on our side.
+// Withdrawals are never retried.
+func WithTradeRetryPolicy(policy *RetryPolicy) Option {
+	return func(c *client) {
+		c.tradeRetry = policy
+	}
+}
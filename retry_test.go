@@ -0,0 +1,86 @@
+package poloniex
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{name: "nil", err: nil},
+		{name: "5xx", err: newAPIError(http.StatusBadGateway, "returnTicker", "Bad Gateway"), retryable: true},
+		{name: "rate limited", err: newAPIError(http.StatusTooManyRequests, "returnTicker", ""), retryable: true},
+		{name: "too many calls per second", err: newAPIError(http.StatusOK, "returnTicker", "Please do not make more than 8 API calls per second."), retryable: true},
+		{name: "unexpected response", err: &APIError{StatusCode: http.StatusOK, Kind: ErrorKindUnexpectedResponse}, retryable: true},
+		{name: "rejected", err: newAPIError(http.StatusOK, "returnTicker", "Invalid currency pair.")},
+		{name: "connection reset", err: syscall.ECONNRESET, retryable: true},
+		{name: "unexpected EOF", err: io.ErrUnexpectedEOF, retryable: true},
+		{name: "canceled", err: context.Canceled},
+		{name: "deadline exceeded", err: context.DeadlineExceeded},
+		{name: "other", err: errors.New("poloniex: unable to decode")},
+	}
+
+	for _, tt := range tests {
+		if retryable := IsRetryable(tt.err); retryable != tt.retryable {
+			t.Errorf("%s: IsRetryable = %v, expected %v", tt.name, retryable, tt.retryable)
+		}
+	}
+}
+
+func TestPublicCallIsRetried(t *testing.T) {
+	s := newFakeServer(t)
+	s.respondHTML("returnCurrencies", http.StatusBadGateway, `<html>502 Bad Gateway</html>`)
+	s.respond("returnCurrencies", http.StatusOK, `{"BTC":{"id":28,"name":"Bitcoin","txFee":"0.00050000","minConf":1,"depositAddress":null,"disabled":0,"delisted":0,"frozen":0}}`)
+
+	c := newTestClient(s, WithRetryPolicy(&RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+
+	currencies, err := c.GetCurrencies(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(currencies) != 1 || currencies[0].Name != "BTC" {
+		t.Errorf("unexpected currencies %v", currencies)
+	}
+
+	if n := s.callCount("returnCurrencies"); n != 2 {
+		t.Errorf("expected 2 attempts, got %d", n)
+	}
+}
+
+func TestPublicCallRetriesAreBounded(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnCurrencies", http.StatusServiceUnavailable, ``)
+
+	c := newTestClient(s, WithRetryPolicy(&RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+
+	if _, err := c.GetCurrencies(context.Background()); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if n := s.callCount("returnCurrencies"); n != 3 {
+		t.Errorf("expected 3 attempts, got %d", n)
+	}
+}
+
+func TestTradeCallIsNotRetriedByDefault(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("returnBalances", http.StatusBadGateway, ``)
+
+	if _, err := newTestClient(s).GetBalances(context.Background()); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if n := s.callCount("returnBalances"); n != 1 {
+		t.Errorf("expected a single attempt, got %d", n)
+	}
+}
@@ -0,0 +1,195 @@
+package poloniex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrWithdrawalBlocked is returned when a withdrawal is refused by the WithdrawalPolicy of the client.
+var ErrWithdrawalBlocked = errors.New("poloniex: withdrawal blocked by policy")
+
+// WithdrawalPolicy restricts the withdrawals made by a client.
+// As a bug here loses money, it's enforced locally, before any request is signed.
+type WithdrawalPolicy struct {
+	// Allowed destination addresses, by currency.
+	// When set, currencies missing from the map can't be withdrawn at all.
+	// When nil, any address is allowed.
+	AllowedAddresses map[string][]string
+
+	// Maximum amount withdrawn over the last 24 hours, by currency.
+	// Currencies missing from the map are not limited.
+	// Only withdrawals made through this client are counted.
+	DailyMax map[string]Decimal
+}
+
+// WithWithdrawalPolicy sets the policy enforced before every withdrawal.
+// By default, withdrawals are not restricted.
+// Currencies are case insensitive.
+func WithWithdrawalPolicy(policy WithdrawalPolicy) Option {
+	return func(c *client) {
+		c.withdrawalGuard = &withdrawalGuard{policy: normalizeWithdrawalPolicy(policy)}
+	}
+}
+
+// normalizeWithdrawalPolicy returns a copy of policy with upper cased currencies.
+func normalizeWithdrawalPolicy(policy WithdrawalPolicy) WithdrawalPolicy {
+	normalized := WithdrawalPolicy{}
+
+	if policy.AllowedAddresses != nil {
+		normalized.AllowedAddresses = make(map[string][]string, len(policy.AllowedAddresses))
+		for currency, addresses := range policy.AllowedAddresses {
+			currency = strings.ToUpper(currency)
+			normalized.AllowedAddresses[currency] = append(normalized.AllowedAddresses[currency], addresses...)
+		}
+	}
+
+	if policy.DailyMax != nil {
+		normalized.DailyMax = make(map[string]Decimal, len(policy.DailyMax))
+		for currency, max := range policy.DailyMax {
+			currency = strings.ToUpper(currency)
+
+			// Keep the lowest maximum if a currency is given twice with different cases.
+			if current, ok := normalized.DailyMax[currency]; ok && current.LessThan(max) {
+				continue
+			}
+			normalized.DailyMax[currency] = max
+		}
+	}
+
+	return normalized
+}
+
+type WithdrawResult struct {
+	// Message returned by Poloniex, ex: "Withdrew 2398 NXT."
+	Response string
+}
+
+type withdrawResultFromJSON struct {
+	Response string `json:"response"`
+}
+
+func (c *client) Withdraw(ctx context.Context, currency string, amount Decimal, address, paymentID string) (*WithdrawResult, error) {
+	if currency == "" || address == "" {
+		return nil, errors.New("currency and address are required to withdraw")
+	}
+
+	currency = strings.ToUpper(currency)
+
+	if amount.Sign() <= 0 {
+		return nil, fmt.Errorf("poloniex: withdrawal amount must be positive, got %s", amount)
+	}
+
+	var release func()
+	if c.withdrawalGuard != nil {
+		var err error
+		if release, err = c.withdrawalGuard.reserve(c.now(), currency, amount, address); err != nil {
+			c.logger.Warnf("withdrawal of %s %s to %s blocked: %v", amount, currency, address, err)
+
+			return nil, err
+		}
+	}
+
+	params := []postParam{
+		postParam{key: "currency", value: currency},
+		postParam{key: "amount", value: amount.String()},
+		postParam{key: "address", value: address},
+	}
+	if paymentID != "" {
+		params = append(params, postParam{key: "paymentId", value: paymentID})
+	}
+
+	w := &withdrawResultFromJSON{}
+
+	// The retry policy is bypassed, as a withdrawal which timed out may have been made anyway.
+	if err := c.tradeCallOnce(ctx, "withdraw", w, params...); err != nil {
+		// Only a withdrawal rejected by Poloniex is sure not to have happened.
+		// Otherwise, it's still counted in the daily maximum.
+		if release != nil && isRejection(err) {
+			release()
+		}
+
+		return nil, err
+	}
+
+	return &WithdrawResult{Response: w.Response}, nil
+}
+
+// withdrawalGuard enforces a WithdrawalPolicy, keeping track of the withdrawals of the last 24 hours.
+type withdrawalGuard struct {
+	mu          sync.Mutex
+	policy      WithdrawalPolicy
+	withdrawals []*withdrawalRecord
+}
+
+type withdrawalRecord struct {
+	date     time.Time
+	currency string
+	amount   Decimal
+}
+
+// reserve checks that the withdrawal is allowed, and counts it in the daily maximum right away,
+// so that concurrent withdrawals can't exceed it.
+// The returned function cancels the reservation.
+func (g *withdrawalGuard) reserve(now time.Time, currency string, amount Decimal, address string) (func(), error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.policy.AllowedAddresses != nil && !containsString(g.policy.AllowedAddresses[currency], address) {
+		return nil, fmt.Errorf("%w: address %s is not allowed for %s", ErrWithdrawalBlocked, address, currency)
+	}
+
+	// Forget withdrawals older than 24 hours.
+	since := now.Add(-24 * time.Hour)
+	recent := g.withdrawals[:0]
+	for _, w := range g.withdrawals {
+		if w.date.After(since) {
+			recent = append(recent, w)
+		}
+	}
+	g.withdrawals = recent
+
+	if max, ok := g.policy.DailyMax[currency]; ok {
+		total := amount
+		for _, w := range g.withdrawals {
+			if w.currency == currency {
+				total = total.Add(w.amount)
+			}
+		}
+
+		if total.GreaterThan(max) {
+			return nil, fmt.Errorf("%w: %s %s withdrawn over 24 hours would exceed the maximum of %s", ErrWithdrawalBlocked, total, currency, max)
+		}
+	}
+
+	record := &withdrawalRecord{date: now, currency: currency, amount: amount}
+	g.withdrawals = append(g.withdrawals, record)
+
+	release := func() {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+
+		for i, w := range g.withdrawals {
+			if w == record {
+				g.withdrawals = append(g.withdrawals[:i], g.withdrawals[i+1:]...)
+
+				return
+			}
+		}
+	}
+
+	return release, nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
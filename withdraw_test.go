@@ -0,0 +1,197 @@
+package poloniex
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestWithdrawalGuardReserve(t *testing.T) {
+	now := time.Date(2018, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	policy := WithdrawalPolicy{
+		AllowedAddresses: map[string][]string{
+			"BTC": {"btc-address"},
+			"ETH": {"eth-address"},
+		},
+		DailyMax: map[string]Decimal{
+			"BTC": MustParseDecimal("1"),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		previous []*withdrawalRecord
+		currency string
+		amount   string
+		address  string
+		blocked  bool
+	}{
+		{name: "allowed", currency: "BTC", amount: "0.5", address: "btc-address"},
+		{name: "exactly the maximum", currency: "BTC", amount: "1", address: "btc-address"},
+		{name: "above the maximum", currency: "BTC", amount: "1.00000001", address: "btc-address", blocked: true},
+		{name: "unknown address", currency: "BTC", amount: "0.1", address: "other-address", blocked: true},
+		{name: "address of another currency", currency: "BTC", amount: "0.1", address: "eth-address", blocked: true},
+		{name: "currency not allowed", currency: "LTC", amount: "0.1", address: "btc-address", blocked: true},
+		{name: "currency without maximum", currency: "ETH", amount: "1000", address: "eth-address"},
+		{
+			name:     "maximum reached over 24 hours",
+			previous: []*withdrawalRecord{{date: now.Add(-23 * time.Hour), currency: "BTC", amount: MustParseDecimal("0.6")}},
+			currency: "BTC",
+			amount:   "0.5",
+			address:  "btc-address",
+			blocked:  true,
+		},
+		{
+			name:     "withdrawals older than 24 hours are forgotten",
+			previous: []*withdrawalRecord{{date: now.Add(-25 * time.Hour), currency: "BTC", amount: MustParseDecimal("0.6")}},
+			currency: "BTC",
+			amount:   "0.5",
+			address:  "btc-address",
+		},
+		{
+			name:     "other currencies are not counted",
+			previous: []*withdrawalRecord{{date: now.Add(-time.Hour), currency: "ETH", amount: MustParseDecimal("10")}},
+			currency: "BTC",
+			amount:   "1",
+			address:  "btc-address",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &withdrawalGuard{policy: policy, withdrawals: tt.previous}
+
+			release, err := g.reserve(now, tt.currency, MustParseDecimal(tt.amount), tt.address)
+			if tt.blocked {
+				if !errors.Is(err, ErrWithdrawalBlocked) {
+					t.Fatalf("expected ErrWithdrawalBlocked, got %v", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if release == nil {
+				t.Fatal("expected a release function")
+			}
+		})
+	}
+}
+
+func TestWithdrawalGuardRelease(t *testing.T) {
+	now := time.Date(2018, 5, 1, 12, 0, 0, 0, time.UTC)
+	g := &withdrawalGuard{policy: WithdrawalPolicy{DailyMax: map[string]Decimal{"BTC": MustParseDecimal("1")}}}
+
+	release, err := g.reserve(now, "BTC", MustParseDecimal("1"), "address")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := g.reserve(now, "BTC", MustParseDecimal("0.1"), "address"); !errors.Is(err, ErrWithdrawalBlocked) {
+		t.Fatalf("expected ErrWithdrawalBlocked while reserved, got %v", err)
+	}
+
+	release()
+
+	if _, err := g.reserve(now, "BTC", MustParseDecimal("0.1"), "address"); err != nil {
+		t.Fatalf("unexpected error after release: %v", err)
+	}
+}
+
+func TestWithdraw(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("withdraw", http.StatusOK, `{"response":"Withdrew 2398 NXT."}`)
+
+	c := newTestClient(s)
+
+	r, err := c.Withdraw(context.Background(), "NXT", MustParseDecimal("2398"), "nxt-address", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if r.Response != "Withdrew 2398 NXT." {
+		t.Errorf("unexpected response %q", r.Response)
+	}
+
+	form := s.lastForm("withdraw")
+	if form["currency"] != "NXT" || form["amount"] != "2398" || form["address"] != "nxt-address" {
+		t.Errorf("unexpected parameters %v", form)
+	}
+
+	if _, ok := form["paymentId"]; ok {
+		t.Errorf("unexpected paymentId in %v", form)
+	}
+}
+
+func TestWithdrawDailyMax(t *testing.T) {
+	tests := []struct {
+		name string
+		// Response of Poloniex to the first withdrawal.
+		status  int
+		body    string
+		html    bool
+		counted bool
+	}{
+		{name: "success", status: http.StatusOK, body: `{"response":"Withdrew 1 BTC."}`, counted: true},
+		{name: "rejected", status: http.StatusOK, body: `{"error":"Not enough BTC."}`, counted: false},
+		{name: "rejected with 4xx", status: http.StatusUnprocessableEntity, body: `{"error":"Invalid address."}`, counted: false},
+		{name: "5xx", status: http.StatusBadGateway, body: `{"error":"Internal error."}`, counted: true},
+		{name: "5xx without message", status: http.StatusBadGateway, body: ``, counted: true},
+		{name: "HTML page", status: http.StatusOK, body: `<html>Checking your browser</html>`, html: true, counted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newFakeServer(t)
+			if tt.html {
+				s.respondHTML("withdraw", tt.status, tt.body)
+			} else {
+				s.respond("withdraw", tt.status, tt.body)
+			}
+
+			c := newTestClient(s,
+				WithWithdrawalPolicy(WithdrawalPolicy{DailyMax: map[string]Decimal{"BTC": MustParseDecimal("1")}}),
+				WithTradeRetryPolicy(&RetryPolicy{MaxAttempts: 3}),
+			)
+
+			c.Withdraw(context.Background(), "BTC", MustParseDecimal("1"), "address", "")
+
+			if n := s.callCount("withdraw"); n != 1 {
+				t.Fatalf("expected the withdrawal to be sent once, sent %d times", n)
+			}
+
+			_, err := c.Withdraw(context.Background(), "BTC", MustParseDecimal("1"), "address", "")
+			if blocked := errors.Is(err, ErrWithdrawalBlocked); blocked != tt.counted {
+				t.Fatalf("expected the first withdrawal to be counted: %v, got error %v", tt.counted, err)
+			}
+		})
+	}
+}
+
+func TestWithdrawPolicyIsCaseInsensitive(t *testing.T) {
+	s := newFakeServer(t)
+	s.respond("withdraw", http.StatusOK, `{"response":"Withdrew 1 BTC."}`)
+
+	c := newTestClient(s, WithWithdrawalPolicy(WithdrawalPolicy{
+		AllowedAddresses: map[string][]string{"btc": {"address"}},
+		DailyMax:         map[string]Decimal{"Btc": MustParseDecimal("1")},
+	}))
+
+	if _, err := c.Withdraw(context.Background(), "btc", MustParseDecimal("0.6"), "address", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if form := s.lastForm("withdraw"); form["currency"] != "BTC" {
+		t.Errorf("expected currency BTC, got %q", form["currency"])
+	}
+
+	if _, err := c.Withdraw(context.Background(), "BTC", MustParseDecimal("0.6"), "address", ""); !errors.Is(err, ErrWithdrawalBlocked) {
+		t.Fatalf("expected ErrWithdrawalBlocked, got %v", err)
+	}
+}